@@ -1,12 +1,99 @@
 package logx
 
 import (
+	"bytes"
 	"time"
 )
 
+// Storer is the original adapter interface, it receives a pre-rendered line.
 type Storer interface {
 	Init(config string) error
 	WriteMsg(when time.Time, msg string, level int) error
 	Destroy()
 	Flush()
 }
+
+// RecordStorer is the record based adapter interface.
+// The record is only valid during WriteRecord, it must not be retained.
+type RecordStorer interface {
+	Init(config string) error
+	WriteRecord(r *Record) error
+	Destroy()
+	Flush()
+}
+
+// SyncCloser is implemented by adapters whose flush and close can fail,
+// Logger.FlushContext and Logger.Shutdown call Sync and Close instead of
+// Flush and Destroy and return their errors.
+type SyncCloser interface {
+	Sync() error
+	Close() error
+}
+
+// Record is a single log entry.
+type Record struct {
+	Time    time.Time
+	Level   int
+	Message string
+
+	// caller, File is empty when caller reporting is disabled
+	// and Func is empty unless enabled by SetCallerFunc
+	File string
+	Line int
+	Func string
+
+	Fields []Field
+	// Name is the name of the Logger, see Logger.Named
+	Name string
+
+	// Stack is the stack of the calling goroutine, see SetStacktraceLevel
+	Stack string
+}
+
+// legacyMsg renders r the way Storer.WriteMsg expects it, see writeLegacyMsg.
+func (r *Record) legacyMsg() string {
+	b := getBuffer()
+	writeLegacyMsg(b, r)
+	msg := b.String()
+	putBuffer(b)
+
+	return msg
+}
+
+func (r *Record) reset() {
+	*r = Record{}
+}
+
+// storerShim makes a Storer usable as a RecordStorer.
+type storerShim struct {
+	Storer
+}
+
+// SetErrorReporter hands report to the Storer if it is an ErrorReporter.
+func (s storerShim) SetErrorReporter(report func(op string, err error)) {
+	if er, ok := s.Storer.(ErrorReporter); ok {
+		er.SetErrorReporter(report)
+	}
+}
+
+func (s storerShim) WriteRecord(r *Record) error {
+	b := getBuffer()
+	writeLegacyMsg(b, r)
+	if r.Stack != "" {
+		b.WriteByte('\n')
+		writeIndentedStack(b, r.Stack)
+		b.Truncate(len(bytes.TrimSuffix(b.Bytes(), []byte("\n"))))
+	}
+	msg := b.String()
+	putBuffer(b)
+
+	return s.WriteMsg(r.Time, msg, r.Level)
+}
+
+func toRecordStorer(s Storer) RecordStorer {
+	if rs, ok := s.(RecordStorer); ok {
+		return rs
+	}
+
+	return storerShim{s}
+}
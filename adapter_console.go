@@ -12,11 +12,13 @@ const (
 )
 
 type consoleWriter struct {
-	lg    *logWriter
-	Color bool `json:"color"`
+	lg        *logWriter
+	formatter Formatter
+	Color     bool   `json:"color"`
+	Format    string `json:"format"`
 }
 
-func newAdapterConsole() Storer {
+func newAdapterConsole() RecordStorer {
 	w := &consoleWriter{
 		lg:    newLogWriter(os.Stdout),
 		Color: runtime.GOOS != "windows",
@@ -25,21 +27,53 @@ func newAdapterConsole() Storer {
 	return w
 }
 
+// Init console logger with json config.
+// jsonConfig like:
+//
+//	{
+//	"color":true,
+//	"format":"text"
+//	}
+//
+// color only applies to the text format.
 func (c *consoleWriter) Init(jsonConfig string) error {
 	err := json.Unmarshal([]byte(jsonConfig), c)
 	if runtime.GOOS == "windows" {
 		c.Color = false
 	}
+	if err != nil {
+		return err
+	}
+
+	if c.Format == "" || c.Format == FormatText {
+		c.formatter = &textFormatter{color: c.Color}
+		return nil
+	}
+
+	c.formatter, err = newFormatter(c.Format)
 	return err
 }
 
 // WriteMsg write message in console.
 func (c *consoleWriter) WriteMsg(when time.Time, msg string, level int) error {
 	if c.Color {
-		msg = colors[level](msg)
+		msg = levelColor(level, msg)
 	}
-	c.lg.println(when, msg)
-	return nil
+	return c.lg.println(when, msg)
+}
+
+// WriteRecord write record in console.
+func (c *consoleWriter) WriteRecord(r *Record) error {
+	b := getBuffer()
+	c.formatter.Format(b, r)
+	err := c.lg.write(b.Bytes())
+
+	putBuffer(b)
+	return err
+}
+
+func (c *consoleWriter) adapterStats() (bytes, rotations int64) {
+	return c.lg.written.Load(), 0
 }
 
 func (c *consoleWriter) Destroy() {
@@ -61,12 +95,3 @@ func newBrush(color string) brush {
 		return pre + color + "m" + text + reset
 	}
 }
-
-var colors = []brush{
-	newBrush("1;37"), // Debug            white
-	newBrush("1;34"), // Info             blue
-	newBrush("1;33"), // Warn             yellow
-	newBrush("1;31"), // Error            red
-	newBrush("1;32"), // Panic            green
-	newBrush("1;36"), // Fatal            cyan
-}
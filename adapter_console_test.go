@@ -1,6 +1,7 @@
 package logx
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 )
@@ -43,3 +44,48 @@ func TestConsoleNoColor(t *testing.T) {
 	log.AddLogger("console", `{"color":false}`)
 	testConsoleCalls(log)
 }
+
+func TestConsoleWithFields(t *testing.T) {
+	log := NewLogger()
+	log.AddLogger("console", `{"color":false}`)
+	child := log.With("request_id", 42, "user", "foo bar")
+	testConsoleCalls(child.With("odd"))
+}
+
+func TestConsoleFormat(t *testing.T) {
+	for _, format := range []string{"text", "json", "logfmt"} {
+		log := NewLogger()
+		log.AddLogger("console", `{"format":"`+format+`"}`)
+		testConsoleCalls(log.Named("console").With("k", "v"))
+	}
+
+	log := NewLogger()
+	if err := log.AddLogger("console", `{"format":"xml"}`); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestConsoleWriteError(t *testing.T) {
+	var reported []error
+	log := NewLogger()
+	log.SetErrorHandler(func(adapter, op string, err error) {
+		reported = append(reported, err)
+	})
+	log.AddLogger("console", "")
+	log.getOutputs()[0].RecordStorer.(*consoleWriter).lg = newLogWriter(failingWriter{})
+	log.Info("info")
+
+	if len(reported) != 1 || !errors.Is(reported[0], ErrWrite) {
+		t.Fatalf("got reported %v", reported)
+	}
+	if st := log.Stats().Adapters["console"]; st.Errors != 1 || st.Bytes != 0 {
+		t.Fatalf("got %+v", st)
+	}
+}
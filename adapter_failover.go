@@ -0,0 +1,220 @@
+package logx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	AdapterFailover = "failover"
+)
+
+const defaultFailoverProbe = 30 * time.Second
+
+// failoverWriter writes to the first working adapter of an ordered list.
+// A failed write is retried on the next adapter, which is used from then on,
+// and the preferred adapters are tried again every probe interval.
+type failoverWriter struct {
+	Adapters []struct {
+		Name   string          `json:"name"`
+		Config json.RawMessage `json:"config"`
+	} `json:"adapters"`
+	Probe string `json:"probe"`
+
+	children []failoverChild
+	probe    time.Duration
+
+	lock      sync.Mutex // serializes the writes and the transitions
+	active    int        // index of the child in use
+	nextProbe time.Time  // when the children before active are tried again
+
+	failovers, recoveries atomic.Int64 // see Logger.Stats
+
+	report func(op string, err error) // handed to the adapters
+}
+
+type failoverChild struct {
+	RecordStorer
+	name string
+}
+
+func newAdapterFailover() RecordStorer {
+	return &failoverWriter{}
+}
+
+// Init failover logger with json config, the adapters are builtin adapters
+// in order of preference. jsonConfig like:
+//
+//	{
+//	"adapters":[
+//		{"name":"file","config":{"filename":"logs/app.log"}},
+//		{"name":"console","config":{"color":false}}
+//	],
+//	"probe":"30s"
+//	}
+func (w *failoverWriter) Init(jsonConfig string) error {
+	err := json.Unmarshal([]byte(jsonConfig), w)
+	if err != nil {
+		return err
+	}
+	if len(w.Adapters) == 0 {
+		return errors.New("logx: failover needs adapters")
+	}
+
+	w.probe = defaultFailoverProbe
+	if w.Probe != "" {
+		w.probe, err = time.ParseDuration(w.Probe)
+		if err != nil {
+			return fmt.Errorf("logx: invalid probe %q: %v", w.Probe, err)
+		}
+	}
+
+	for _, v := range w.Adapters {
+		storer := newAdapter(v.Name)
+		if storer == nil {
+			w.Destroy()
+			return fmt.Errorf("logx: unknown adaptername %q", v.Name)
+		}
+
+		if er, ok := storer.(ErrorReporter); ok && w.report != nil {
+			er.SetErrorReporter(w.report)
+		}
+
+		cfg := string(v.Config)
+		if cfg == "" {
+			cfg = "{}"
+		}
+		if err = storer.Init(cfg); err != nil {
+			// the adapters already initialized hold files and goroutines
+			w.Destroy()
+			return fmt.Errorf("logx: init failover adapter(%s) error:%v", v.Name, err)
+		}
+		w.children = append(w.children, failoverChild{RecordStorer: storer, name: v.Name})
+	}
+
+	return nil
+}
+
+// WriteRecord writes r to the active adapter, or to the next working one.
+func (w *failoverWriter) WriteRecord(r *Record) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := time.Now()
+	start := w.active
+	if start > 0 && !now.Before(w.nextProbe) {
+		start = 0
+	}
+
+	var errs []error
+	for i := start; i < len(w.children); i++ {
+		err := w.children[i].WriteRecord(r)
+		if err == nil {
+			// the record is written, so the failures are not returned
+			if w.report != nil {
+				for _, err := range errs {
+					w.report(OpWrite, err)
+				}
+			}
+			w.switchTo(i, now, errors.Join(errs...))
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", w.children[i].name, err))
+	}
+
+	if start < w.active {
+		w.nextProbe = now.Add(w.probe)
+	}
+	return errors.Join(errs...)
+}
+
+// switchTo makes child i the active one, reporting the transition to it.
+// cause is the error of the adapters before it.
+func (w *failoverWriter) switchTo(i int, now time.Time, cause error) {
+	if i != 0 {
+		// probed or failed over
+		w.nextProbe = now.Add(w.probe)
+	}
+	if i == w.active {
+		return
+	}
+
+	r := recordPool.Get().(*Record)
+	r.Time = now
+	r.Fields = []Field{{Key: "from", Value: w.children[w.active].name}, {Key: "to", Value: w.children[i].name}}
+	if i > w.active {
+		w.failovers.Add(1)
+		r.Level = LevelWarn
+		r.Message = "failover from " + w.children[w.active].name + " to " + w.children[i].name
+		r.Fields = append(r.Fields, Field{Key: "error", Value: cause})
+	} else {
+		w.recoveries.Add(1)
+		r.Level = LevelInfo
+		r.Message = "failover recovered from " + w.children[w.active].name + " to " + w.children[i].name
+	}
+	w.active = i
+
+	w.children[i].WriteRecord(r)
+	putRecord(r)
+}
+
+// SetErrorReporter implements ErrorReporter.
+func (w *failoverWriter) SetErrorReporter(report func(op string, err error)) {
+	w.report = report
+}
+
+func (w *failoverWriter) adapterStats() (bytes, rotations int64) {
+	for _, v := range w.children {
+		if sa, ok := v.RecordStorer.(statsAdapter); ok {
+			n, r := sa.adapterStats()
+			bytes += n
+			rotations += r
+		}
+	}
+
+	return
+}
+
+func (w *failoverWriter) failoverStats() (failovers, recoveries int64) {
+	return w.failovers.Load(), w.recoveries.Load()
+}
+
+func (w *failoverWriter) Destroy() {
+	w.Close()
+}
+
+// Close closes all adapters.
+func (w *failoverWriter) Close() error {
+	var errs []error
+	for _, v := range w.children {
+		if sc, ok := v.RecordStorer.(SyncCloser); ok {
+			errs = append(errs, sc.Close())
+		} else {
+			v.Destroy()
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (w *failoverWriter) Flush() {
+	w.Sync()
+}
+
+// Sync flushes all adapters.
+func (w *failoverWriter) Sync() error {
+	var errs []error
+	for _, v := range w.children {
+		if sc, ok := v.RecordStorer.(SyncCloser); ok {
+			errs = append(errs, sc.Sync())
+		} else {
+			v.Flush()
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package logx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// flakyStorer fails while down is set.
+type flakyStorer struct {
+	recordStorer
+	down bool
+}
+
+func (s *flakyStorer) WriteRecord(r *Record) error {
+	if s.down {
+		return errors.New("disk full")
+	}
+
+	return s.recordStorer.WriteRecord(r)
+}
+
+func recordMessages(records []Record) []string {
+	msgs := []string{}
+	for _, r := range records {
+		msgs = append(msgs, r.Message)
+	}
+	return msgs
+}
+
+func TestFailover(t *testing.T) {
+	var reported []error
+	log := NewLogger()
+	log.SetErrorHandler(func(adapter, op string, err error) {
+		reported = append(reported, err)
+	})
+	err := log.AddLogger(AdapterFailover, `{"adapters":[{"name":"console"},{"name":"console","config":{"color":false}}],"probe":"1h"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	primary, secondary := &flakyStorer{}, &recordStorer{}
+	fw := log.getOutputs()[0].RecordStorer.(*failoverWriter)
+	fw.children[0] = failoverChild{RecordStorer: primary, name: "primary"}
+	fw.children[1] = failoverChild{RecordStorer: secondary, name: "secondary"}
+
+	log.Info("1")
+	primary.down = true
+	log.Info("2")
+	log.Info("3")
+
+	// recovers once probed
+	primary.down = false
+	log.Info("4")
+	fw.nextProbe = time.Time{}
+	log.Info("5")
+
+	if got, want := recordMessages(primary.records), []string{"1", "5",
+		"failover recovered from secondary to primary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("primary got %q, want %q", got, want)
+	}
+	if got, want := recordMessages(secondary.records), []string{"2",
+		"failover from primary to secondary", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("secondary got %q, want %q", got, want)
+	}
+
+	st := log.Stats().Adapters[AdapterFailover]
+	if st.Failovers != 1 || st.Recoveries != 1 {
+		t.Errorf("got %+v", st)
+	}
+	// the failed write of "2" to the primary
+	if len(reported) != 1 || !errors.Is(reported[0], ErrWrite) ||
+		reported[0].Error() != "logx: write adapter(failover) error:primary: disk full" {
+		t.Errorf("got reported %v", reported)
+	}
+
+	if err := NewLogger().AddLogger(AdapterFailover, `{"adapters":[{"name":"unknown"}]}`); err == nil {
+		t.Error("unknown child adapter accepted")
+	}
+}
+
+func TestFailoverMultifile(t *testing.T) {
+	log := NewLogger()
+	log.SetErrorHandler(func(adapter, op string, err error) {})
+	err := log.AddLogger(AdapterFailover, `{"adapters":[{"name":"multifile","config":{"filename":"`+
+		t.TempDir()+`/app.log","separate":["info"]}},{"name":"console"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	secondary := &recordStorer{}
+	fw := log.getOutputs()[0].RecordStorer.(*failoverWriter)
+	fw.children[1] = failoverChild{RecordStorer: secondary, name: "secondary"}
+
+	// the disk behind the separated file fails
+	fw.children[0].RecordStorer.(*multifileWriter).writers[0].file.Close()
+	log.Info("1")
+
+	if got, want := recordMessages(secondary.records), []string{"1",
+		"failover from multifile to secondary"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("secondary got %q, want %q", got, want)
+	}
+	if st := log.Stats().Adapters[AdapterFailover]; st.Failovers != 1 {
+		t.Errorf("got %+v", st)
+	}
+}
+
+func TestFailoverInitError(t *testing.T) {
+	dir := t.TempDir()
+	w := newAdapterFailover().(*failoverWriter)
+	err := w.Init(`{"adapters":[{"name":"file","config":{"filename":"` + dir +
+		`/app.log"}},{"name":"file","config":{"filename":"` + dir + `/missing/app.log"}}]}`)
+	if err == nil {
+		t.Fatal("init error not returned")
+	}
+
+	// the first file adapter is closed
+	f := w.children[0].RecordStorer.(*fileWriter)
+	if _, err := f.file.Write([]byte("x")); err == nil {
+		t.Fatal("file left open")
+	}
+	select {
+	case <-f.stop:
+	default:
+		t.Fatal("daily rotation not stopped")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -41,16 +42,27 @@ type fileWriter struct {
 
 	Perm string `json:"perm"`
 
+	Format    string `json:"format"`
+	formatter Formatter
+
 	filePrefix, fileExt string // like "project.log", project is filePrefix and .log is fileExt
+
+	written, rotations atomic.Int64 // see Logger.Stats
+
+	stop     chan struct{} // closed by Close, stops dailyRotate
+	stopOnce sync.Once
+
+	report func(op string, err error) // nil prints to stderr
 }
 
 // newAdapterFile create a FileWriter returning as LoggerInterface.
-func newAdapterFile() Storer {
+func newAdapterFile() RecordStorer {
 	w := &fileWriter{
 		Filename: "app.log",
 		Daily:    true,
 		MaxDay:   30,
 		Perm:     "0644",
+		stop:     make(chan struct{}),
 	}
 	return w
 }
@@ -63,7 +75,8 @@ func newAdapterFile() Storer {
 //	"maxsize":1024,
 //	"daily":true,
 //	"maxday":15,
-//  "perm":"0600"
+//  "perm":"0600",
+//	"format":"json"
 //	}
 func (w *fileWriter) Init(jsonConfig string) error {
 	err := json.Unmarshal([]byte(jsonConfig), w)
@@ -71,7 +84,12 @@ func (w *fileWriter) Init(jsonConfig string) error {
 		return err
 	}
 
-	w.fileExt, w.filePrefix = splitFilename(w.Filename)
+	w.formatter, err = newFormatter(w.Format)
+	if err != nil {
+		return err
+	}
+
+	w.filePrefix, w.fileExt = splitFilename(w.Filename)
 
 	w.rotate = w.MaxLine > 0 || w.MaxSize > 0
 
@@ -99,33 +117,147 @@ func (w *fileWriter) needRotateByMax() bool {
 
 // WriteMsg write logger message into file.
 func (w *fileWriter) WriteMsg(when time.Time, msg string, level int) error {
-	msg = when.Format(timeLayout) + " " + msg + "\n"
+	b := getBuffer()
+	writeTime(b, when, getTimeLayout())
+	b.WriteByte(' ')
+	b.WriteString(msg)
+	b.WriteByte('\n')
+	err := w.write(when, b.Bytes())
+
+	putBuffer(b)
+	return err
+}
+
+// WriteRecord write record into file.
+func (w *fileWriter) WriteRecord(r *Record) error {
+	b := getBuffer()
+	w.formatter.Format(b, r)
+	err := w.write(r.Time, b.Bytes())
+
+	putBuffer(b)
+	return err
+}
 
+// write writes one rendered line, rotating first if needed.
+func (w *fileWriter) write(when time.Time, msg []byte) error {
 	if w.rotate {
 		w.RLock()
 		if w.needRotateByMax() {
 			w.RUnlock()
 
 			w.Lock()
-			if err := w.doRotate(when, false); err != nil {
-				fmt.Fprintf(os.Stderr, "FileWriter(%q): %s\n", w.Filename, err)
-			}
+			err := w.doRotate(when, false)
 			w.Unlock()
+			if err != nil {
+				w.reportError(OpRotate, err)
+			}
 		} else {
 			w.RUnlock()
 		}
 	}
 
 	w.Lock()
-	_, err := w.file.Write([]byte(msg))
+	_, err := w.file.Write(msg)
 	if err == nil {
 		w.maxLineCurLine++
 		w.maxSizeCurSize += len(msg)
 	}
 	w.Unlock()
+
+	if err == nil {
+		w.written.Add(int64(len(msg)))
+	}
 	return err
 }
 
+// WriteBatch writes records with one write, split only where the file rotates.
+func (w *fileWriter) WriteBatch(records []Record) error {
+	b := getBuffer()
+	defer putBuffer(b)
+
+	var buf [128]int
+	ends := buf[:0]
+	for i := range records {
+		w.formatter.Format(b, &records[i])
+		ends = append(ends, b.Len())
+	}
+
+	var rotateErrs []error
+	defer func() {
+		// reported unlocked, the handler may log to w
+		for _, err := range rotateErrs {
+			w.reportError(OpRotate, err)
+		}
+	}()
+
+	w.Lock()
+	defer w.Unlock()
+
+	p := b.Bytes()
+	start, lines := 0, 0 // the pending chunk
+	for i := range records {
+		// like write, rotate before the record which would exceed the limits
+		end := 0
+		if i > 0 {
+			end = ends[i-1]
+		}
+		if w.rotate && w.needRotateWith(lines, end-start) {
+			if err := w.writeChunk(p[start:end], lines); err != nil {
+				return err
+			}
+			start, lines = end, 0
+
+			if err := w.doRotate(records[i].Time, false); err != nil {
+				rotateErrs = append(rotateErrs, err)
+			}
+		}
+		lines++
+	}
+
+	return w.writeChunk(p[start:], lines)
+}
+
+// needRotateWith is needRotateByMax after the pending lines and size are written.
+func (w *fileWriter) needRotateWith(lines, size int) bool {
+	return (w.MaxLine > 0 && w.maxLineCurLine+lines >= w.MaxLine) ||
+		(w.MaxSize > 0 && w.maxSizeCurSize+size >= w.MaxSize)
+}
+
+// writeChunk writes p holding lines records, w must be locked.
+func (w *fileWriter) writeChunk(p []byte, lines int) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	_, err := w.file.Write(p)
+	if err != nil {
+		return err
+	}
+
+	w.maxLineCurLine += lines
+	w.maxSizeCurSize += len(p)
+	w.written.Add(int64(len(p)))
+	return nil
+}
+
+// SetErrorReporter implements ErrorReporter.
+func (w *fileWriter) SetErrorReporter(report func(op string, err error)) {
+	w.report = report
+}
+
+func (w *fileWriter) reportError(op string, err error) {
+	if w.report != nil {
+		w.report(op, err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "FileWriter(%q): %s\n", w.Filename, err)
+}
+
+func (w *fileWriter) adapterStats() (bytes, rotations int64) {
+	return w.written.Load(), w.rotations.Load()
+}
+
 func (w *fileWriter) createLogFile() (*os.File, error) {
 	// Open the log file
 	perm, err := strconv.ParseUint(w.Perm, 8, 32)
@@ -172,10 +304,12 @@ func (w *fileWriter) dailyRotate() {
 	select {
 	case <-tm.C:
 		w.Lock()
-		if err := w.doRotate(time.Now(), true); err != nil {
-			fmt.Fprintf(os.Stderr, "FileWriter(%q): %s\n", w.Filename, err)
-		}
+		err := w.doRotate(time.Now(), true)
 		w.Unlock()
+		if err != nil {
+			w.reportError(OpRotate, err)
+		}
+	case <-w.stop:
 	}
 	tm.Stop()
 }
@@ -242,6 +376,7 @@ RESTART_LOGGER:
 		return fmt.Errorf("Rotate StartLogger: %s\n", startLoggerErr)
 	}
 
+	w.rotations.Add(1)
 	go w.deleteOldLog()
 
 	return nil
@@ -287,7 +422,7 @@ func (w *fileWriter) deleteOldLog() {
 	filepath.Walk(dir, func(path string, info os.FileInfo, err error) (returnErr error) {
 		defer func() {
 			if r := recover(); r != nil {
-				fmt.Fprintf(os.Stderr, "Unable to delete old log '%s', error: %v\n", path, r)
+				w.reportError(OpRetention, fmt.Errorf("Unable to delete old log '%s', error: %v", path, r))
 			}
 		}()
 
@@ -298,7 +433,9 @@ func (w *fileWriter) deleteOldLog() {
 		if !info.IsDir() && info.ModTime().Add(24*time.Hour*time.Duration(w.MaxDay)).Before(now) {
 			if strings.HasPrefix(filepath.Base(path), filepath.Base(w.filePrefix)) &&
 				strings.HasSuffix(filepath.Base(path), w.fileExt) {
-				os.Remove(path)
+				if err := os.Remove(path); err != nil {
+					w.reportError(OpRetention, err)
+				}
 			}
 		}
 		return
@@ -307,16 +444,32 @@ func (w *fileWriter) deleteOldLog() {
 
 // Destroy close the file description, close file writer.
 func (w *fileWriter) Destroy() {
+	w.Close()
+}
+
+// Close closes the file.
+func (w *fileWriter) Close() error {
+	w.stopOnce.Do(func() {
+		if w.stop != nil {
+			close(w.stop)
+		}
+	})
+
 	w.Lock()
-	w.file.Close()
-	w.Unlock()
+	defer w.Unlock()
+	return w.file.Close()
 }
 
 // Flush flush file logger.
 // there are no buffering messages in file logger in memory.
 // flush file means sync file from disk.
 func (w *fileWriter) Flush() {
+	w.Sync()
+}
+
+// Sync commits the file to disk.
+func (w *fileWriter) Sync() error {
 	w.Lock()
-	w.file.Sync()
-	w.Unlock()
+	defer w.Unlock()
+	return w.file.Sync()
 }
@@ -2,7 +2,9 @@ package logx
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -18,11 +20,15 @@ const (
 type multifileWriter struct {
 	writers    []*fileWriter
 	fullWriter *fileWriter
-	Separate   []string `json:"separate"`
-	IsFull     bool     `json:"full"`
-}
+	Separate   []string    `json:"separate"`
+	IsFull     bool        `json:"full"`
+	levelIndex map[int]int // level -> index of writers
+
+	batchLock sync.Mutex
+	subset    []Record // reused by WriteBatch
 
-var levelIndex map[int]int
+	report func(op string, err error) // handed to the writers
+}
 
 // Init file logger with json config.
 // jsonConfig like:
@@ -33,6 +39,7 @@ var levelIndex map[int]int
 //	"daily":true,
 //	"maxday":30,
 //  "perm":"0600",
+//	"format":"text",
 //	"full":false,
 //	"separate":["debug","info","warn","error","panic","fatal"],
 //	}
@@ -43,19 +50,19 @@ func (w *multifileWriter) Init(jsonConfig string) error {
 		return err
 	}
 
-	levelIndex = map[int]int{}
+	w.levelIndex = map[int]int{}
 	for i, v := range w.Separate {
 		level := GetLevelByName(v)
 
-		_, ok := levelIndex[level]
+		_, ok := w.levelIndex[level]
 		if ok {
 			panic(fmt.Sprintf("double Level(%s)", v))
 		}
 
-		levelIndex[level] = i
+		w.levelIndex[level] = i
 	}
 
-	if len(levelIndex) == 0 {
+	if len(w.levelIndex) == 0 {
 		panic(fmt.Sprint("empty Separate Level"))
 	}
 
@@ -74,8 +81,10 @@ func (w *multifileWriter) Init(jsonConfig string) error {
 		jsonMap["filename"] = filePrefix + "." + v + fileExt
 		bs, _ := json.Marshal(jsonMap)
 		writer := newAdapterFile().(*fileWriter)
+		writer.SetErrorReporter(w.report)
 		err = writer.Init(string(bs))
 		if err != nil {
+			w.Close()
 			return err
 		}
 		w.writers = append(w.writers, writer)
@@ -85,8 +94,10 @@ func (w *multifileWriter) Init(jsonConfig string) error {
 		jsonMap["filename"] = filePrefix + fileExt
 		bs, _ := json.Marshal(jsonMap)
 		writer := newAdapterFile().(*fileWriter)
+		writer.SetErrorReporter(w.report)
 		err = writer.Init(string(bs))
 		if err != nil {
+			w.Close()
 			return err
 		}
 		w.fullWriter = writer
@@ -95,15 +106,33 @@ func (w *multifileWriter) Init(jsonConfig string) error {
 	return nil
 }
 
+// SetErrorReporter implements ErrorReporter.
+func (w *multifileWriter) SetErrorReporter(report func(op string, err error)) {
+	w.report = report
+}
+
 func (w *multifileWriter) Destroy() {
+	w.Close()
+}
+
+// Close closes all files.
+func (w *multifileWriter) Close() error {
+	return w.each((*fileWriter).Close)
+}
+
+// each calls fn for every writer and joins the errors.
+func (w *multifileWriter) each(fn func(*fileWriter) error) error {
+	var errs []error
 	for i := 0; i < len(w.writers); i++ {
 		if w.writers[i] != nil {
-			w.writers[i].Destroy()
+			errs = append(errs, fn(w.writers[i]))
 		}
 	}
-	if w.IsFull {
-		w.fullWriter.Destroy()
+	if w.IsFull && w.fullWriter != nil {
+		errs = append(errs, fn(w.fullWriter))
 	}
+
+	return errors.Join(errs...)
 }
 
 func (w *multifileWriter) WriteMsg(when time.Time, msg string, level int) error {
@@ -111,7 +140,7 @@ func (w *multifileWriter) WriteMsg(when time.Time, msg string, level int) error
 		w.fullWriter.WriteMsg(when, msg, level)
 	}
 
-	v, ok := levelIndex[level]
+	v, ok := w.levelIndex[level]
 	if ok {
 		w.writers[v].WriteMsg(when, msg, level)
 	}
@@ -119,17 +148,87 @@ func (w *multifileWriter) WriteMsg(when time.Time, msg string, level int) error
 	return nil
 }
 
-func (w *multifileWriter) Flush() {
-	for i := 0; i < len(w.writers); i++ {
-		if w.writers[i] != nil {
-			w.writers[i].Flush()
+func (w *multifileWriter) WriteRecord(r *Record) error {
+	v, ok := w.levelIndex[r.Level]
+	if !ok && !w.IsFull {
+		return nil
+	}
+
+	// all writers share the config, so the record is formatted once
+	f := w.fullWriter
+	if ok {
+		f = w.writers[v]
+	}
+	b := getBuffer()
+	f.formatter.Format(b, r)
+
+	var errs []error
+	if w.IsFull {
+		errs = append(errs, w.fullWriter.write(r.Time, b.Bytes()))
+	}
+	if ok {
+		errs = append(errs, w.writers[v].write(r.Time, b.Bytes()))
+	}
+
+	putBuffer(b)
+	return errors.Join(errs...)
+}
+
+func (w *multifileWriter) WriteBatch(records []Record) error {
+	var err error
+	if w.IsFull {
+		err = w.fullWriter.WriteBatch(records)
+	}
+
+	w.batchLock.Lock()
+	defer w.batchLock.Unlock()
+
+	for i, writer := range w.writers {
+		w.subset = w.subset[:0]
+		for _, r := range records {
+			if v, ok := w.levelIndex[r.Level]; ok && v == i {
+				w.subset = append(w.subset, r)
+			}
+		}
+		if len(w.subset) == 0 {
+			continue
+		}
+
+		if e := writer.WriteBatch(w.subset); e != nil && err == nil {
+			err = e
 		}
 	}
+	for i := range w.subset {
+		w.subset[i] = Record{}
+	}
+
+	return err
+}
+
+func (w *multifileWriter) adapterStats() (bytes, rotations int64) {
+	for _, v := range w.writers {
+		n, r := v.adapterStats()
+		bytes += n
+		rotations += r
+	}
 	if w.IsFull {
-		w.fullWriter.Flush()
+		n, r := w.fullWriter.adapterStats()
+		bytes += n
+		rotations += r
 	}
+
+	return
+}
+
+func (w *multifileWriter) Flush() {
+	w.Sync()
+}
+
+// Sync commits all files to disk.
+func (w *multifileWriter) Sync() error {
+	return w.each((*fileWriter).Sync)
 }
 
-func newAdapterMultifile() Storer {
+func newAdapterMultifile() RecordStorer {
 	return &multifileWriter{IsFull: false}
 }
@@ -2,6 +2,8 @@ package logx
 
 import (
 	"bufio"
+	"encoding/json"
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -97,3 +99,24 @@ func TestMutifile_Full(t *testing.T) {
 		os.Remove(file)
 	}
 }
+
+func TestMutifile_Format(t *testing.T) {
+	log := NewLogger()
+	log.AddLogger("multifile", `{"filename":"test.log","format":"json","separate":["info"]}`)
+	log.With("k", 1).Info("info")
+
+	file := "test.info.log"
+	defer os.Remove(file)
+
+	bs, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{}
+	if err = json.Unmarshal(bs, &m); err != nil {
+		t.Fatal(file, string(bs), err)
+	}
+	if m["msg"] != "info" || m["level"] != "info" || m["k"] != float64(1) {
+		t.Fatal(file + " " + string(bs) + " unexpected json")
+	}
+}
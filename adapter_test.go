@@ -0,0 +1,95 @@
+package logx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type legacyStorer struct {
+	msgs []string
+}
+
+func (s *legacyStorer) Init(config string) error { return nil }
+func (s *legacyStorer) Destroy()                 {}
+func (s *legacyStorer) Flush()                   {}
+
+func (s *legacyStorer) WriteMsg(when time.Time, msg string, level int) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+type recordStorer struct {
+	records []Record
+}
+
+func (s *recordStorer) Init(config string) error { return nil }
+func (s *recordStorer) Destroy()                 {}
+func (s *recordStorer) Flush()                   {}
+
+func (s *recordStorer) WriteRecord(r *Record) error {
+	s.records = append(s.records, *r)
+	return nil
+}
+
+func TestStorerShim(t *testing.T) {
+	s := &legacyStorer{}
+	log := NewLogger()
+	log.SetShortfile(true)
+	log.AddStorer("legacy", s)
+	log.With("k", "v").Warn("warn")
+
+	if len(s.msgs) != 1 {
+		t.Fatal("got", len(s.msgs), "messages not 1")
+	}
+	if !strings.HasPrefix(s.msgs[0], "[W] [adapter_test.go:") ||
+		!strings.HasSuffix(s.msgs[0], "] warn k=v") {
+		t.Fatal("unexpected message", s.msgs[0])
+	}
+}
+
+func TestRecordStorer(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.SetCallerFunc(true)
+	log.AddRecordStorer("record", s)
+	log.Named("db").Named("pool").With("k", "v").Info("info")
+
+	if len(s.records) != 1 {
+		t.Fatal("got", len(s.records), "records not 1")
+	}
+	r := s.records[0]
+	if r.Level != LevelInfo || r.Message != "info" || r.Name != "db.pool" ||
+		len(r.Fields) != 1 || r.Fields[0].Key != "k" || r.Line == 0 ||
+		!strings.HasSuffix(r.Func, "TestRecordStorer") {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestAdapterLevel(t *testing.T) {
+	s1, s2 := &recordStorer{}, &recordStorer{}
+	log := NewLogger()
+	log.AddRecordStorer("all", s1)
+	log.AddRecordStorer("warn", s2, `{"level":"warn"}`)
+	log.Info("info")
+	log.Warn("warn")
+
+	if len(s1.records) != 2 || len(s2.records) != 1 || s2.records[0].Message != "warn" {
+		t.Fatal("unexpected records", s1.records, s2.records)
+	}
+
+	if err := log.SetAdapterLevel("all", LevelError); err != nil {
+		t.Fatal(err)
+	}
+	log.Warn("warn")
+	if len(s1.records) != 2 || len(s2.records) != 2 {
+		t.Fatal("unexpected records", s1.records, s2.records)
+	}
+
+	if err := log.SetAdapterLevel("none", LevelError); err == nil {
+		t.Fatal("unknown adapter accepted")
+	}
+	if err := log.AddRecordStorer("bad", &recordStorer{}, `{"level":"nope"}`); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+}
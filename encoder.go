@@ -0,0 +1,353 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// The encoders below append to a pooled *bytes.Buffer, formatting numbers and
+// times through a stack scratch buffer, so that rendering a record does not
+// allocate for the common field types.
+
+const hexDigits = "0123456789abcdef"
+
+func getBuffer() *bytes.Buffer {
+	b := msgBufPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// maxPooledBuffer keeps a huge line from pinning its buffer in the pool.
+const maxPooledBuffer = 64 << 10
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	msgBufPool.Put(b)
+}
+
+func writeInt(b *bytes.Buffer, n int64) {
+	var scratch [24]byte
+	b.Write(strconv.AppendInt(scratch[:0], n, 10))
+}
+
+func writeUint(b *bytes.Buffer, n uint64) {
+	var scratch [24]byte
+	b.Write(strconv.AppendUint(scratch[:0], n, 10))
+}
+
+// writeFloat writes f like fmt's %v.
+func writeFloat(b *bytes.Buffer, f float64, bitSize int) {
+	var scratch [32]byte
+	b.Write(strconv.AppendFloat(scratch[:0], f, 'g', -1, bitSize))
+}
+
+func writeTime(b *bytes.Buffer, t time.Time, layout string) {
+	var scratch [64]byte
+	b.Write(t.AppendFormat(scratch[:0], layout))
+}
+
+// writeCaller writes file:line.
+func writeCaller(b *bytes.Buffer, r *Record) {
+	b.WriteString(r.File)
+	b.WriteByte(':')
+	writeInt(b, int64(r.Line))
+}
+
+func writeJSONCaller(b *bytes.Buffer, r *Record) {
+	b.WriteByte('"')
+	writeJSONEscaped(b, r.File)
+	b.WriteByte(':')
+	writeInt(b, int64(r.Line))
+	b.WriteByte('"')
+}
+
+func writeFieldCaller(b *bytes.Buffer, r *Record) {
+	if needsQuote(r.File) {
+		writeQuoted(b, r.File+":"+strconv.Itoa(r.Line))
+		return
+	}
+
+	writeCaller(b, r)
+}
+
+// writeLegacyMsg renders r the way Storer.WriteMsg expects it,
+// like: [I] [main.go:10 main.main] name: msg k=v
+func writeLegacyMsg(b *bytes.Buffer, r *Record) {
+	b.WriteString(levelPrefix(r.Level))
+
+	if r.File != "" {
+		b.WriteByte('[')
+		writeCaller(b, r)
+		if r.Func != "" {
+			b.WriteByte(' ')
+			b.WriteString(shortFuncName(r.Func))
+		}
+		b.WriteString("] ")
+	}
+	if r.Name != "" {
+		b.WriteString(r.Name)
+		b.WriteString(": ")
+	}
+
+	b.WriteString(r.Message)
+	if len(r.Fields) > 0 {
+		b.WriteByte(' ')
+		writeFields(b, r.Fields)
+	}
+}
+
+// writeFields writes fields like: request_id=1 user="foo bar"
+func writeFields(b *bytes.Buffer, fields []Field) {
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		writeFieldValue(b, f.Value)
+	}
+}
+
+// writeFieldValue writes v as a logfmt value, quoted if needed.
+func writeFieldValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		writeFieldString(b, v)
+	case int:
+		writeInt(b, int64(v))
+	case int8:
+		writeInt(b, int64(v))
+	case int16:
+		writeInt(b, int64(v))
+	case int32:
+		writeInt(b, int64(v))
+	case int64:
+		writeInt(b, v)
+	case uint:
+		writeUint(b, uint64(v))
+	case uint8:
+		writeUint(b, uint64(v))
+	case uint16:
+		writeUint(b, uint64(v))
+	case uint32:
+		writeUint(b, uint64(v))
+	case uint64:
+		writeUint(b, v)
+	case float32:
+		writeFloat(b, float64(v), 32)
+	case float64:
+		writeFloat(b, v, 64)
+	case bool:
+		if v {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case time.Duration:
+		writeFieldString(b, v.String())
+	case error:
+		writeFieldString(b, v.Error())
+	case []string:
+		writeFieldList(b, v)
+	default:
+		writeFieldString(b, fmt.Sprint(v))
+	}
+}
+
+func writeFieldString(b *bytes.Buffer, s string) {
+	if s != "" && !needsQuote(s) {
+		b.WriteString(s)
+		return
+	}
+
+	writeQuoted(b, s)
+}
+
+func writeQuoted(b *bytes.Buffer, s string) {
+	var scratch [128]byte
+	b.Write(strconv.AppendQuote(scratch[:0], s))
+}
+
+func needsQuote(s string) bool {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ' ', c == '=', c == '"', c < 0x20, c == 0x7f:
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeFieldList writes list as one quoted value, like: "[\"a b\",\"c\"]"
+func writeFieldList(b *bytes.Buffer, list []string) {
+	lb := getBuffer()
+
+	lb.WriteByte('[')
+	for i, v := range list {
+		if i > 0 {
+			lb.WriteByte(',')
+		}
+		lb.WriteString(strconv.Quote(v))
+	}
+	lb.WriteByte(']')
+
+	b.WriteString(strconv.Quote(lb.String()))
+	putBuffer(lb)
+}
+
+// writeJSONString writes s as a json string, invalid UTF-8 is replaced by U+FFFD.
+func writeJSONString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	writeJSONEscaped(b, s)
+	b.WriteByte('"')
+}
+
+func writeJSONEscaped(b *bytes.Buffer, s string) {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+
+		if c < utf8.RuneSelf {
+			b.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteString(`\u00`)
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteString(s[start:i])
+			b.WriteString("\ufffd")
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 break javascript, escaped like encoding/json
+		if r == '\u2028' || r == '\u2029' {
+			b.WriteString(s[start:i])
+			b.WriteString(`\u202`)
+			b.WriteByte(hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+
+	b.WriteString(s[start:])
+}
+
+// writeJSONValue writes v as json, falling back to encoding/json.
+func writeJSONValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case string:
+		writeJSONString(b, v)
+	case int:
+		writeInt(b, int64(v))
+	case int8:
+		writeInt(b, int64(v))
+	case int16:
+		writeInt(b, int64(v))
+	case int32:
+		writeInt(b, int64(v))
+	case int64:
+		writeInt(b, v)
+	case uint:
+		writeUint(b, uint64(v))
+	case uint8:
+		writeUint(b, uint64(v))
+	case uint16:
+		writeUint(b, uint64(v))
+	case uint32:
+		writeUint(b, uint64(v))
+	case uint64:
+		writeUint(b, v)
+	case float32:
+		writeJSONFloat(b, float64(v), 32)
+	case float64:
+		writeJSONFloat(b, v, 64)
+	case bool:
+		if v {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case time.Duration:
+		writeJSONString(b, v.String())
+	case error:
+		writeJSONString(b, v.Error())
+	case []string:
+		b.WriteByte('[')
+		for i, s := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeJSONString(b, s)
+		}
+		b.WriteByte(']')
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			writeJSONString(b, fmt.Sprint(v))
+			return
+		}
+		b.Write(bs)
+	}
+}
+
+// writeJSONFloat writes f like encoding/json, but NaN and Inf,
+// which json lacks, as strings.
+func writeJSONFloat(b *bytes.Buffer, f float64, bitSize int) {
+	var scratch [32]byte
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b.WriteByte('"')
+		writeFloat(b, f, bitSize)
+		b.WriteByte('"')
+		return
+	}
+
+	// exponent only for very small or large values
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	bs := strconv.AppendFloat(scratch[:0], f, format, -1, bitSize)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(bs); n >= 4 && bs[n-4] == 'e' && bs[n-3] == '-' && bs[n-2] == '0' {
+			bs[n-2] = bs[n-1]
+			bs = bs[:n-1]
+		}
+	}
+	b.Write(bs)
+}
@@ -0,0 +1,91 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWriteJSONString(t *testing.T) {
+	for _, s := range []string{
+		"", "plain", `quote " and \ backslash`, "tab\tnew\nline\r",
+		"\x00\x1f\x7f", "中文", "\u2028\u2029", "bad \xff utf8",
+	} {
+		b := &bytes.Buffer{}
+		writeJSONString(b, s)
+
+		var got string
+		if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+			t.Fatalf("%q: invalid json %s: %v", s, b.String(), err)
+		}
+
+		want, _ := json.Marshal(s)
+		var wantS string
+		json.Unmarshal(want, &wantS)
+		if got != wantS {
+			t.Errorf("%q: got %q, want %q", s, got, wantS)
+		}
+	}
+}
+
+func TestWriteFieldValue(t *testing.T) {
+	for _, v := range []interface{}{
+		"", "a b", "a=b", 42, int64(-1), uint8(7), 1.5, 1e6, float32(0.1),
+		true, time.Second, errors.New("boom"), nil, struct{ A int }{1},
+	} {
+		b := &bytes.Buffer{}
+		writeFieldValue(b, v)
+
+		// same as the former fmt.Sprint based rendering
+		want := fmt.Sprint(v)
+		if want == "" || needsQuote(want) {
+			want = fmt.Sprintf("%q", want)
+		}
+		if b.String() != want {
+			t.Errorf("%#v: got %s, want %s", v, b.String(), want)
+		}
+	}
+}
+
+func TestWriteJSONValue(t *testing.T) {
+	for _, v := range []interface{}{
+		"s", 42, int64(-1), uint64(math.MaxUint64), 1.5, 1e21, 1e-7, float32(0.1),
+		false, nil, []string{"a", "b"}, map[string]int{"a": 1},
+	} {
+		b := &bytes.Buffer{}
+		writeJSONValue(b, v)
+
+		want, _ := json.Marshal(v)
+		if b.String() != string(want) {
+			t.Errorf("%#v: got %s, want %s", v, b.String(), want)
+		}
+	}
+}
+
+func TestLoggerAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not stable with the race detector")
+	}
+
+	for _, format := range []string{FormatText, FormatJSON, FormatLogfmt} {
+		l := NewLogger()
+		l.SetShortfile(true)
+		l.AddLogger(AdapterConsole, `{"format":"`+format+`"}`)
+		l.getOutputs()[0].RecordStorer.(*consoleWriter).lg = newLogWriter(io.Discard)
+		child := l.With("request_id", 42, "user", "foo bar")
+		err := errors.New("failed")
+
+		n := testing.AllocsPerRun(100, func() {
+			child.Info("done")
+			l.ErrInfo(err)
+		})
+		if n != 0 {
+			t.Errorf("%s: %v allocs per run, want 0", format, n)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package logx
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// Formatter renders a record as one line, including the trailing newline.
+type Formatter interface {
+	Format(b *bytes.Buffer, r *Record)
+}
+
+var (
+	formattersLock sync.RWMutex
+	formatters     = map[string]func() Formatter{
+		FormatText:   func() Formatter { return &textFormatter{} },
+		FormatJSON:   func() Formatter { return &jsonFormatter{} },
+		FormatLogfmt: func() Formatter { return &logfmtFormatter{} },
+	}
+)
+
+// RegisterFormatter makes a formatter available to the "format" key of adapter configs.
+// newFn is called once per adapter, so formatters may keep state.
+func RegisterFormatter(name string, newFn func() Formatter) {
+	if newFn == nil {
+		panic("logx: invalid Formatter")
+	}
+
+	formattersLock.Lock()
+	formatters[name] = newFn
+	formattersLock.Unlock()
+}
+
+// newFormatter returns the formatter registered as name, "" means text.
+func newFormatter(name string) (Formatter, error) {
+	if name == "" {
+		name = FormatText
+	}
+
+	formattersLock.RLock()
+	newFn, ok := formatters[name]
+	formattersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("logx: unknown format %q", name)
+	}
+
+	return newFn(), nil
+}
+
+// textFormatter keeps the original layout, like:
+// 2016-01-02 15:04:05 [I] [main.go:10] msg k=v
+type textFormatter struct {
+	color bool
+}
+
+func (f *textFormatter) Format(b *bytes.Buffer, r *Record) {
+	writeTime(b, r.Time, getTimeLayout())
+	b.WriteByte(' ')
+	if color := levelColorCode(r.Level); f.color && color != "" {
+		b.WriteString("\033[")
+		b.WriteString(color)
+		b.WriteByte('m')
+		writeLegacyMsg(b, r)
+		b.WriteString("\033[0m")
+	} else {
+		writeLegacyMsg(b, r)
+	}
+	b.WriteByte('\n')
+
+	if r.Stack != "" {
+		writeIndentedStack(b, r.Stack)
+	}
+}
+
+// jsonFormatter writes one json object per line, like:
+// {"time":"2016-01-02T15:04:05.999+08:00","level":"info","caller":"main.go:10","msg":"msg","k":"v"}
+type jsonFormatter struct{}
+
+func (f *jsonFormatter) Format(b *bytes.Buffer, r *Record) {
+	b.WriteString(`{"time":`)
+	b.WriteByte('"')
+	writeTime(b, r.Time, time.RFC3339Nano)
+	b.WriteByte('"')
+	b.WriteString(`,"level":`)
+	writeJSONString(b, levelName(r.Level))
+	if r.Name != "" {
+		b.WriteString(`,"logger":`)
+		writeJSONString(b, r.Name)
+	}
+	if r.File != "" {
+		b.WriteString(`,"caller":`)
+		writeJSONCaller(b, r)
+	}
+	if r.Func != "" {
+		b.WriteString(`,"func":`)
+		writeJSONString(b, r.Func)
+	}
+	b.WriteString(`,"msg":`)
+	writeJSONString(b, r.Message)
+
+	for _, v := range r.Fields {
+		b.WriteByte(',')
+		writeJSONString(b, v.Key)
+		b.WriteByte(':')
+		writeJSONValue(b, v.Value)
+	}
+
+	if r.Stack != "" {
+		b.WriteString(`,"stack":`)
+		writeJSONString(b, r.Stack)
+	}
+	b.WriteString("}\n")
+}
+
+// logfmtFormatter writes key=value pairs, like:
+// time=2016-01-02T15:04:05.999+08:00 level=info caller=main.go:10 msg=msg k=v
+type logfmtFormatter struct{}
+
+func (f *logfmtFormatter) Format(b *bytes.Buffer, r *Record) {
+	b.WriteString("time=")
+	writeTime(b, r.Time, time.RFC3339Nano)
+	b.WriteString(" level=")
+	b.WriteString(levelName(r.Level))
+	if r.Name != "" {
+		b.WriteString(" logger=")
+		writeFieldString(b, r.Name)
+	}
+	if r.File != "" {
+		b.WriteString(" caller=")
+		writeFieldCaller(b, r)
+	}
+	if r.Func != "" {
+		b.WriteString(" func=")
+		writeFieldString(b, r.Func)
+	}
+	b.WriteString(" msg=")
+	writeFieldString(b, r.Message)
+
+	if len(r.Fields) > 0 {
+		b.WriteByte(' ')
+		writeFields(b, r.Fields)
+	}
+
+	if r.Stack != "" {
+		b.WriteString(" stack=")
+		writeQuoted(b, r.Stack)
+	}
+	b.WriteByte('\n')
+}
@@ -0,0 +1,136 @@
+package logx
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+type levelDef struct {
+	name   string
+	prefix string
+	color  string // ANSI SGR code, "" means no color
+}
+
+// levelTable is never modified once stored, RegisterLevel stores a new one.
+type levelTable struct {
+	byValue map[int]levelDef
+	byName  map[string]int
+}
+
+var (
+	levelsLock sync.Mutex
+	levels     atomic.Value // *levelTable
+)
+
+func init() {
+	t := &levelTable{
+		byValue: map[int]levelDef{
+			LevelDebug: {"debug", "[D] ", "1;37"}, // white
+			LevelInfo:  {"info", "[I] ", "1;34"},  // blue
+			LevelWarn:  {"warn", "[W] ", "1;33"},  // yellow
+			LevelError: {"error", "[E] ", "1;31"}, // red
+			LevelPanic: {"panic", "[P] ", "1;32"}, // green
+			LevelFatal: {"fatal", "[F] ", "1;36"}, // cyan
+		},
+		byName: map[string]int{},
+	}
+	for k, v := range t.byValue {
+		t.byName[v.name] = k
+	}
+
+	levels.Store(t)
+}
+
+// RegisterLevel adds a level, like:
+//
+//	RegisterLevel("trace", LevelDebug-1, "[T] ", "0;37")
+//	RegisterLevel("audit", LevelFatal+1, "[A] ", "1;35")
+//
+// value orders it against the other levels, prefix is used by the text format
+// and color is an ANSI SGR code for the console, "" means no color.
+// The builtin levels are the consecutive values LevelDebug to LevelFatal, so
+// value is below LevelDebug or above LevelFatal, a level above LevelFatal
+// passes any SetLevel up to LevelFatal. Only Panic and Fatal records skip the
+// sampling and the overflow policy.
+// Log or Logf write messages at a registered level, GetLevelByName and the
+// "level" of adapter configs find it by name.
+// It should be called before the loggers are configured.
+func RegisterLevel(name string, value int, prefix string, color string) error {
+	if name == "" {
+		return fmt.Errorf("logx: invalid level name")
+	}
+
+	levelsLock.Lock()
+	defer levelsLock.Unlock()
+
+	old := levels.Load().(*levelTable)
+	if _, ok := old.byName[name]; ok {
+		return fmt.Errorf("logx: level %q already registered", name)
+	}
+	if v, ok := old.byValue[value]; ok {
+		return fmt.Errorf("logx: level value %d already registered as %q", value, v.name)
+	}
+
+	t := &levelTable{
+		byValue: make(map[int]levelDef, len(old.byValue)+1),
+		byName:  make(map[string]int, len(old.byName)+1),
+	}
+	for k, v := range old.byValue {
+		t.byValue[k] = v
+	}
+	for k, v := range old.byName {
+		t.byName[k] = v
+	}
+
+	t.byValue[value] = levelDef{name: name, prefix: prefix, color: color}
+	t.byName[name] = value
+
+	levels.Store(t)
+	return nil
+}
+
+// lookupLevel returns the level registered as name, builtin or added by
+// RegisterLevel. GetLevelByName exports it.
+func lookupLevel(name string) (int, bool) {
+	v, ok := levels.Load().(*levelTable).byName[name]
+	return v, ok
+}
+
+// levelName returns the registered name of level, like "info".
+func levelName(level int) string {
+	if v, ok := levels.Load().(*levelTable).byValue[level]; ok {
+		return v.name
+	}
+
+	return strconv.Itoa(level)
+}
+
+func levelPrefix(level int) string {
+	if v, ok := levels.Load().(*levelTable).byValue[level]; ok {
+		return v.prefix
+	}
+
+	return "[" + strconv.Itoa(level) + "] "
+}
+
+// levelColor paints s with the color of level.
+func levelColor(level int, s string) string {
+	if v, ok := levels.Load().(*levelTable).byValue[level]; ok && v.color != "" {
+		return newBrush(v.color)(s)
+	}
+
+	return s
+}
+
+// levelColorCode returns the ANSI SGR code of level, "" means no color.
+func levelColorCode(level int) string {
+	return levels.Load().(*levelTable).byValue[level].color
+}
+
+// isPanicOrFatal reports whether level is Panic or Fatal, whose records are
+// never sampled nor dropped.
+func isPanicOrFatal(level int) bool {
+	return level == LevelPanic || level == LevelFatal
+}
@@ -0,0 +1,87 @@
+package logx
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testLevelTrace = LevelDebug - 1
+	testLevelAudit = LevelFatal + 1
+)
+
+var registerTestLevels sync.Once
+
+func testLevels(t *testing.T) {
+	registerTestLevels.Do(func() {
+		if err := RegisterLevel("trace", testLevelTrace, "[T] ", "0;37"); err != nil {
+			t.Fatal(err)
+		}
+		if err := RegisterLevel("audit", testLevelAudit, "[A] ", "1;35"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestRegisterLevel(t *testing.T) {
+	testLevels(t)
+
+	if err := RegisterLevel("trace", -100, "", ""); err == nil {
+		t.Fatal("duplicate name accepted")
+	}
+	if err := RegisterLevel("other", LevelInfo, "", ""); err == nil {
+		t.Fatal("duplicate value accepted")
+	}
+	if GetLevelByName("audit") != testLevelAudit || levelName(testLevelTrace) != "trace" {
+		t.Fatal("level not registered")
+	}
+
+	s := &recordStorer{}
+	log := NewLogger()
+	log.SetLevel(LevelInfo)
+	log.AddRecordStorer("record", s, `{"level":"audit"}`)
+	log.AddLogger("console", "")
+	log.Log(testLevelTrace, "trace")
+	log.Log(testLevelAudit, "audit")
+	log.SetLevel(testLevelTrace)
+	log.Logf(testLevelTrace, "%s", "trace")
+
+	if len(s.records) != 1 || s.records[0].Level != testLevelAudit {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+
+	// above LevelFatal, but sampled unlike Fatal
+	s.records = nil
+	log.SetLevel(LevelFatal)
+	log.SetSampling(time.Hour, 1, 0)
+	defer log.SetSampling(0, 0, 0)
+	for i := 0; i < 3; i++ {
+		log.Log(testLevelAudit, "audit")
+	}
+	if len(s.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(s.records))
+	}
+}
+
+func TestMutifile_CustomLevel(t *testing.T) {
+	testLevels(t)
+
+	log := NewLogger()
+	log.AddLogger("multifile", `{"filename":"test.log","separate":["audit"]}`)
+	log.Info("info")
+	log.Log(testLevelAudit, "audit")
+
+	file := "test.audit.log"
+	defer os.Remove(file)
+
+	log.Flush()
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() == 0 {
+		t.Fatal(file, "is empty")
+	}
+}
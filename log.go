@@ -2,6 +2,11 @@
 
 package logx
 
+import (
+	"context"
+	"time"
+)
+
 const (
 	LevelDebug = iota
 	LevelInfo
@@ -11,7 +16,6 @@ const (
 	LevelFatal
 )
 
-var levelPrefix = [LevelFatal + 1]string{"[D] ", "[I] ", "[W] ", "[E] ", "[P] ", "[F] "}
 var defaultLogger *Logger
 
 func init() {
@@ -24,6 +28,43 @@ func SetLevel(level int) {
 	defaultLogger.SetLevel(level)
 }
 
+func SetStacktraceLevel(level int) {
+	defaultLogger.SetStacktraceLevel(level)
+}
+
+func SetCallerFunc(b bool) {
+	defaultLogger.SetCallerFunc(b)
+}
+
+func SetVModule(spec string) error {
+	return defaultLogger.SetVModule(spec)
+}
+
+// AddHook adds a hook to the default logger, see Logger.AddHook.
+func AddHook(levels []int, fn func(r *Record) error) {
+	defaultLogger.AddHook(levels, fn)
+}
+
+func SetOverflowPolicy(p OverflowPolicy, timeout ...time.Duration) {
+	defaultLogger.SetOverflowPolicy(p, timeout...)
+}
+
+func SetBatch(size int, wait time.Duration) {
+	defaultLogger.SetBatch(size, wait)
+}
+
+func FlushContext(ctx context.Context) error {
+	return defaultLogger.FlushContext(ctx)
+}
+
+func Shutdown(ctx context.Context) error {
+	return defaultLogger.Shutdown(ctx)
+}
+
+func SetSampling(tick time.Duration, first, thereafter int) {
+	defaultLogger.SetSampling(tick, first, thereafter)
+}
+
 func SetOutput(l *Logger) {
 	if l == nil {
 		panic("logx: invalid Logger")
@@ -31,6 +72,11 @@ func SetOutput(l *Logger) {
 	defaultLogger = l
 }
 
+// With returns a child of the default logger carrying the given key/value pairs.
+func With(kv ...interface{}) *Logger {
+	return defaultLogger.With(kv...)
+}
+
 // --- output
 func Debug(v ...interface{}) {
 	defaultLogger.Debugf(generateFmtStr(len(v)), v...)
@@ -56,6 +102,10 @@ func Fatal(v ...interface{}) {
 	defaultLogger.Fatalf(generateFmtStr(len(v)), v...)
 }
 
+func Log(level int, v ...interface{}) {
+	defaultLogger.Logf(level, generateFmtStr(len(v)), v...)
+}
+
 func Debugf(format string, v ...interface{}) {
 	defaultLogger.Debugf(format, v...)
 }
@@ -80,12 +130,68 @@ func Fatalf(format string, v ...interface{}) {
 	defaultLogger.Fatalf(format, v...)
 }
 
+func Logf(level int, format string, v ...interface{}) {
+	defaultLogger.Logf(level, format, v...)
+}
+
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.DebugCtx(ctx, v...)
+}
+
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.InfoCtx(ctx, v...)
+}
+
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.WarnCtx(ctx, v...)
+}
+
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.ErrorCtx(ctx, v...)
+}
+
+func PanicCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.PanicCtx(ctx, v...)
+}
+
+func FatalCtx(ctx context.Context, v ...interface{}) {
+	defaultLogger.FatalCtx(ctx, v...)
+}
+
+func DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.DebugfCtx(ctx, format, v...)
+}
+
+func InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.InfofCtx(ctx, format, v...)
+}
+
+func WarnfCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.WarnfCtx(ctx, format, v...)
+}
+
+func ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.ErrorfCtx(ctx, format, v...)
+}
+
+func PanicfCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.PanicfCtx(ctx, format, v...)
+}
+
+func FatalfCtx(ctx context.Context, format string, v ...interface{}) {
+	defaultLogger.FatalfCtx(ctx, format, v...)
+}
+
+func ErrorErr(err error, msg string, kv ...interface{}) {
+	defaultLogger.ErrorErr(err, msg, kv...)
+}
+
 func ErrDebug(err error) {
 	if err == nil {
 		return
 	}
 
-	defaultLogger.Debugf("%v", err)
+	defaultLogger.ErrDebug(err)
 }
 
 func ErrInfo(err error) {
@@ -93,7 +199,7 @@ func ErrInfo(err error) {
 		return
 	}
 
-	defaultLogger.Infof("%v", err)
+	defaultLogger.ErrInfo(err)
 }
 
 func ErrWarn(err error) {
@@ -101,7 +207,7 @@ func ErrWarn(err error) {
 		return
 	}
 
-	defaultLogger.Warnf("%v", err)
+	defaultLogger.ErrWarn(err)
 }
 
 func ErrError(err error) {
@@ -109,7 +215,7 @@ func ErrError(err error) {
 		return
 	}
 
-	defaultLogger.Errorf("%v", err)
+	defaultLogger.ErrError(err)
 }
 
 func ErrPanic(err error) {
@@ -117,7 +223,7 @@ func ErrPanic(err error) {
 		return
 	}
 
-	defaultLogger.Panicf("%v", err)
+	defaultLogger.ErrPanic(err)
 }
 
 func ErrFatal(err error) {
@@ -125,5 +231,5 @@ func ErrFatal(err error) {
 		return
 	}
 
-	defaultLogger.Fatalf("%v", err)
+	defaultLogger.ErrFatal(err)
 }
@@ -4,34 +4,42 @@ import (
 	"bytes"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type logWriter struct {
 	sync.Mutex
-	writer io.Writer
+	writer  io.Writer
+	written atomic.Int64 // bytes, see Logger.Stats
 }
 
 func newLogWriter(w io.Writer) *logWriter {
 	return &logWriter{writer: w}
 }
 
-func (lw *logWriter) println(when time.Time, msg string) {
-	lw.Lock()
-
-	buf := msgBufPool.Get()
-	b := buf.(*bytes.Buffer)
-	b.Reset()
+func (lw *logWriter) println(when time.Time, msg string) error {
+	b := getBuffer()
 
-	b.WriteString(when.Format(timeLayout))
+	writeTime(b, when, getTimeLayout())
 	b.WriteString(" ")
 	b.WriteString(msg)
 	b.WriteString("\n")
-	lw.writer.Write(b.Bytes())
+	err := lw.write(b.Bytes())
 
-	msgBufPool.Put(b)
+	putBuffer(b)
+	return err
+}
 
+// write writes a line rendered by a Formatter, only the bytes written are
+// counted.
+func (lw *logWriter) write(b []byte) error {
+	lw.Lock()
+	n, err := lw.writer.Write(b)
 	lw.Unlock()
+
+	lw.written.Add(int64(n))
+	return err
 }
 
 var msgBufPool = &sync.Pool{
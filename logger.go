@@ -1,72 +1,251 @@
 package logx
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"math"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Logger struct {
-	lock          sync.Mutex
-	level         int
-	isShortfile   bool
-	funcCallDepth int
-	msgChanLen    int64
-	msgChan       chan *logMsg
-	signalChan    chan string
-	wg            sync.WaitGroup
-	outputs       []*nameLogger
+	*logCore
+	fields []Field
+	name   string
+}
+
+// logCore is the state shared by a Logger and all of its children.
+// Logging never takes lock, it only reads atomics and the outputs snapshot.
+type logCore struct {
+	lock          sync.Mutex // serializes reconfiguration
+	level         atomic.Int64
+	isShortfile   atomic.Bool
+	funcCallDepth atomic.Int64
+	msgChanLen    atomic.Int64 // stored once msgChan is ready
+	msgChan       chan *Record
+	signalChan    chan asyncSignal
+	asyncDone     chan struct{}                 // closed when the async worker exits
+	outputs       atomic.Pointer[[]*nameLogger] // never modified, see setOutputs
+	vmodule       atomic.Pointer[vmodule]
+	sampler       atomic.Pointer[sampler]
+	hooks         atomic.Pointer[[]hook] // never modified, see AddHook
+	stats         loggerStats
+
+	overflowPolicy  atomic.Int64 // OverflowPolicy
+	overflowTimeout atomic.Int64 // time.Duration
+	batchSize       atomic.Int64
+	batchWait       atomic.Int64 // time.Duration
+
+	stacktraceLevel atomic.Int64
+	callerFunc      atomic.Bool
+
+	// see Shutdown
+	closed    atomic.Bool // records are written to stderr
+	closeOnce sync.Once
+	closeDone chan struct{}
+	closeErr  error
+
+	errorHandler atomic.Pointer[ErrorHandler]
+	errorLimiter errorLimiter
 }
 
 type nameLogger struct {
-	Storer
-	name string
+	RecordStorer
+	name  string
+	level atomic.Int64
+	dedup atomic.Pointer[dedup]       // nil writes every record
+	queue atomic.Pointer[outputQueue] // nil writes in the logging goroutine
+	core  *logCore
+
+	messages, errors atomic.Int64 // see Stats
 }
 
+// levelAll is the level of an adapter without "level" config, it passes everything.
+const levelAll = math.MinInt32
+
 func NewLogger() *Logger {
-	l := new(Logger)
+	l := &Logger{logCore: new(logCore)}
 
-	l.level = LevelDebug
-	l.funcCallDepth = 2
-	l.signalChan = make(chan string, 1)
+	l.level.Store(LevelDebug)
+	l.funcCallDepth.Store(2)
+	l.stacktraceLevel.Store(levelNone)
+	l.batchSize.Store(defaultBatchSize)
+	l.signalChan = make(chan asyncSignal, 1)
+	l.closeDone = make(chan struct{})
 
 	return l
 }
 
 func (l *Logger) AddLogger(adapterName string, config ...string) error {
-	l.lock.Lock()
-	defer l.lock.Unlock()
-
-	cfg := append(config, "{}")[0]
-	if cfg == "" {
-		cfg = "{}"
+	storer := newAdapter(adapterName)
+	if storer == nil {
+		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
 	}
 
-	var storer Storer
+	return l.addStorer(adapterName, storer, config)
+}
+
+// newAdapter returns a new builtin adapter, nil if adapterName is unknown.
+func newAdapter(adapterName string) RecordStorer {
 	switch adapterName {
 	case AdapterConsole:
-		storer = newAdapterConsole()
+		return newAdapterConsole()
 	case AdapterFile:
-		storer = newAdapterFile()
+		return newAdapterFile()
 	case AdapterMultifile:
-		storer = newAdapterMultifile()
+		return newAdapterMultifile()
+	case AdapterFailover:
+		return newAdapterFailover()
 	}
 
+	return nil
+}
+
+// AddStorer adds a custom adapter implementing the original Storer interface.
+func (l *Logger) AddStorer(name string, storer Storer, config ...string) error {
 	if storer == nil {
-		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
+		panic("logx: invalid Storer")
+	}
+
+	return l.addStorer(name, toRecordStorer(storer), config)
+}
+
+// AddRecordStorer adds a custom adapter implementing RecordStorer.
+func (l *Logger) AddRecordStorer(name string, storer RecordStorer, config ...string) error {
+	if storer == nil {
+		panic("logx: invalid RecordStorer")
+	}
+
+	return l.addStorer(name, storer, config)
+}
+
+func (l *Logger) addStorer(adapterName string, storer RecordStorer, config []string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	cfg := append(config, "{}")[0]
+	if cfg == "" {
+		cfg = "{}"
 	}
 
-	err := storer.Init(cfg)
+	nl := &nameLogger{name: adapterName, RecordStorer: storer, core: l.logCore}
+	ac, err := parseAdapterConfig(cfg)
+	if err == nil {
+		if er, ok := storer.(ErrorReporter); ok {
+			er.SetErrorReporter(nl.reportError)
+		}
+		err = storer.Init(cfg)
+	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr,
-			fmt.Sprintf("logx: init adaptername(%s) error:%v", adapterName, err.Error()))
+		l.reportError(adapterName, OpInit, err)
 		return err
 	}
-	l.outputs = append(l.outputs, &nameLogger{name: adapterName, Storer: storer})
+	nl.level.Store(int64(ac.level))
+	nl.setDedup(ac.dedup)
+	if ac.queue > 0 {
+		nl.setQueue(l.logCore, ac.queue, ac.overflow, ac.overflowTimeout)
+	}
+
+	old := l.getOutputs()
+	outputs := make([]*nameLogger, 0, len(old)+1)
+	outputs = append(outputs, old...)
+	l.setOutputs(append(outputs, nl))
+	return nil
+}
+
+func (l *Logger) getOutputs() []*nameLogger {
+	if p := l.outputs.Load(); p != nil {
+		return *p
+	}
+
+	return nil
+}
+
+// setOutputs publishes a new outputs snapshot, l.lock must be held.
+// Snapshots are read without lock, so they must not be modified once stored.
+func (l *Logger) setOutputs(outputs []*nameLogger) {
+	l.outputs.Store(&outputs)
+}
+
+// adapterConfig holds the keys every adapter config accepts.
+type adapterConfig struct {
+	level int
+	dedup time.Duration
+
+	// see SetAdapterQueue
+	queue           int
+	overflow        OverflowPolicy
+	overflowTimeout time.Duration
+}
+
+// parseAdapterConfig reads the keys every adapter config accepts,
+// like: {"level":"warn","dedup":"10s","queue":1000,"overflow":"drop-newest"}
+func parseAdapterConfig(cfg string) (adapterConfig, error) {
+	var c struct {
+		Level           string `json:"level"`
+		Dedup           string `json:"dedup"`
+		Queue           int    `json:"queue"`
+		Overflow        string `json:"overflow"`
+		OverflowTimeout string `json:"overflow_timeout"`
+	}
+	if err := json.Unmarshal([]byte(cfg), &c); err != nil {
+		return adapterConfig{}, err
+	}
+
+	ac := adapterConfig{level: levelAll}
+	if c.Level != "" {
+		level, ok := lookupLevel(c.Level)
+		if !ok {
+			return adapterConfig{}, fmt.Errorf("logx: unknown level %q", c.Level)
+		}
+		ac.level = level
+	}
+	if c.Dedup != "" {
+		d, err := time.ParseDuration(c.Dedup)
+		if err != nil {
+			return adapterConfig{}, fmt.Errorf("logx: invalid dedup %q: %v", c.Dedup, err)
+		}
+		ac.dedup = d
+	}
+
+	ac.queue = c.Queue
+	p, err := parseOverflowPolicy(c.Overflow)
+	if err != nil {
+		return adapterConfig{}, err
+	}
+	ac.overflow = p
+	if c.OverflowTimeout != "" {
+		d, err := time.ParseDuration(c.OverflowTimeout)
+		if err != nil {
+			return adapterConfig{}, fmt.Errorf("logx: invalid overflow_timeout %q: %v", c.OverflowTimeout, err)
+		}
+		ac.overflowTimeout = d
+	}
+
+	return ac, nil
+}
+
+// SetAdapterLevel sets the minimum level of the adapters named adapterName.
+func (l *Logger) SetAdapterLevel(adapterName string, level int) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	found := false
+	for _, v := range l.getOutputs() {
+		if v.name == adapterName {
+			v.level.Store(int64(level))
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
+	}
+
 	return nil
 }
 
@@ -74,24 +253,29 @@ func (l *Logger) DeleteLogger(adapterName string) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
+	old := l.getOutputs()
 	outputs := []*nameLogger{}
-	for _, v := range l.outputs {
+	deleted := []*nameLogger{}
+	for _, v := range old {
 		if v.name == adapterName {
-			v.Destroy()
+			deleted = append(deleted, v)
 		} else {
 			outputs = append(outputs, v)
 		}
 	}
-	if len(outputs) == len(l.outputs) {
+	if len(deleted) == 0 {
 		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
 	}
 
-	l.outputs = outputs
+	l.setOutputs(outputs)
+	for _, v := range deleted {
+		v.Destroy()
+	}
 	return nil
 }
 
 func (l *Logger) Debug(v ...interface{}) {
-	if LevelDebug < l.level {
+	if !l.enabled(LevelDebug) {
 		return
 	}
 
@@ -99,7 +283,7 @@ func (l *Logger) Debug(v ...interface{}) {
 }
 
 func (l *Logger) Info(v ...interface{}) {
-	if LevelInfo < l.level {
+	if !l.enabled(LevelInfo) {
 		return
 	}
 
@@ -107,7 +291,7 @@ func (l *Logger) Info(v ...interface{}) {
 }
 
 func (l *Logger) Warn(v ...interface{}) {
-	if LevelWarn < l.level {
+	if !l.enabled(LevelWarn) {
 		return
 	}
 
@@ -115,7 +299,7 @@ func (l *Logger) Warn(v ...interface{}) {
 }
 
 func (l *Logger) Error(v ...interface{}) {
-	if LevelError < l.level {
+	if !l.enabled(LevelError) {
 		return
 	}
 
@@ -123,7 +307,7 @@ func (l *Logger) Error(v ...interface{}) {
 }
 
 func (l *Logger) Panic(v ...interface{}) {
-	if LevelPanic < l.level {
+	if !l.enabled(LevelPanic) {
 		return
 	}
 
@@ -131,15 +315,24 @@ func (l *Logger) Panic(v ...interface{}) {
 }
 
 func (l *Logger) Fatal(v ...interface{}) {
-	if LevelFatal < l.level {
+	if !l.enabled(LevelFatal) {
 		return
 	}
 
 	l.writeMsg(LevelFatal, generateFmtStr(len(v)), v...)
 }
 
+// Log writes a message at level, which may be registered by RegisterLevel.
+func (l *Logger) Log(level int, v ...interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.writeMsg(level, generateFmtStr(len(v)), v...)
+}
+
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	if LevelDebug < l.level {
+	if !l.enabled(LevelDebug) {
 		return
 	}
 
@@ -147,7 +340,7 @@ func (l *Logger) Debugf(format string, v ...interface{}) {
 }
 
 func (l *Logger) Infof(format string, v ...interface{}) {
-	if LevelInfo < l.level {
+	if !l.enabled(LevelInfo) {
 		return
 	}
 
@@ -155,7 +348,7 @@ func (l *Logger) Infof(format string, v ...interface{}) {
 }
 
 func (l *Logger) Warnf(format string, v ...interface{}) {
-	if LevelWarn < l.level {
+	if !l.enabled(LevelWarn) {
 		return
 	}
 
@@ -163,7 +356,7 @@ func (l *Logger) Warnf(format string, v ...interface{}) {
 }
 
 func (l *Logger) Errorf(format string, v ...interface{}) {
-	if LevelError < l.level {
+	if !l.enabled(LevelError) {
 		return
 	}
 
@@ -171,7 +364,7 @@ func (l *Logger) Errorf(format string, v ...interface{}) {
 }
 
 func (l *Logger) Panicf(format string, v ...interface{}) {
-	if LevelPanic < l.level {
+	if !l.enabled(LevelPanic) {
 		return
 	}
 
@@ -179,189 +372,292 @@ func (l *Logger) Panicf(format string, v ...interface{}) {
 }
 
 func (l *Logger) Fatalf(format string, v ...interface{}) {
-	if LevelFatal < l.level {
+	if !l.enabled(LevelFatal) {
 		return
 	}
 
 	l.writeMsg(LevelFatal, format, v...)
 }
 
+func (l *Logger) Logf(level int, format string, v ...interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+
+	l.writeMsg(level, format, v...)
+}
+
 func (l *Logger) ErrDebug(err error) {
-	if err == nil || LevelDebug < l.level {
+	if err == nil || !l.enabled(LevelDebug) {
 		return
 	}
 
-	l.writeMsg(LevelDebug, "%v", err)
+	l.writeErr(LevelDebug, err, "", nil)
 }
 
 func (l *Logger) ErrInfo(err error) {
-	if err == nil || LevelInfo < l.level {
+	if err == nil || !l.enabled(LevelInfo) {
 		return
 	}
 
-	l.writeMsg(LevelInfo, "%v", err)
+	l.writeErr(LevelInfo, err, "", nil)
 }
 
 func (l *Logger) ErrWarn(err error) {
-	if err == nil || LevelWarn < l.level {
+	if err == nil || !l.enabled(LevelWarn) {
 		return
 	}
 
-	l.writeMsg(LevelWarn, "%v", err)
+	l.writeErr(LevelWarn, err, "", nil)
 }
 
 func (l *Logger) ErrError(err error) {
-	if err == nil || LevelError < l.level {
+	if err == nil || !l.enabled(LevelError) {
 		return
 	}
 
-	l.writeMsg(LevelError, "%v", err)
+	l.writeErr(LevelError, err, "", nil)
 }
 
 func (l *Logger) ErrPanic(err error) {
-	if err == nil || LevelPanic < l.level {
+	if err == nil || !l.enabled(LevelPanic) {
 		return
 	}
 
-	l.writeMsg(LevelPanic, "%v", err)
+	l.writeErr(LevelPanic, err, "", nil)
 }
 
 func (l *Logger) ErrFatal(err error) {
-	if err == nil || LevelFatal < l.level {
+	if err == nil || !l.enabled(LevelFatal) {
 		return
 	}
 
-	l.writeMsg(LevelFatal, "%v", err)
+	l.writeErr(LevelFatal, err, "", nil)
 }
 
 func (l *Logger) writeMsg(level int, msg string, v ...interface{}) error {
 	if len(v) == 0 {
 		panic("logx: Empty Output")
+	}
+
+	frame, ok := l.caller(nil)
+	if !l.allow(level, frame, ok, msg) {
 		return nil
 	}
 
-	msg = fmt.Sprintf(msg, v...)
-	when := time.Now()
+	r := l.newRecord(level, sprintf(msg, v))
+	if ok {
+		l.fillCaller(r, &frame)
+	}
+	l.fillStack(r)
 
-	if l.funcCallDepth > 0 {
-		_, file, line, ok := runtime.Caller(l.funcCallDepth)
-		if !ok {
-			file = "???"
-			line = 0
-		}
+	return l.output(r)
+}
+
+func (l *Logger) fillCaller(r *Record, frame *runtime.Frame) {
+	if l.GetFuncCallDepth() <= 0 {
+		return
+	}
+
+	r.File = frame.File
+	r.Line = frame.Line
+	if l.callerFunc.Load() {
+		r.Func = frame.Function
+	}
 
-		if l.isShortfile {
-			file = filepath.Base(file)
+	if l.isShortfile.Load() {
+		r.File = filepath.Base(r.File)
+	}
+}
+
+// enabled reports whether a message at level may be logged,
+// the vmodule rules are checked once the caller is known.
+func (l *Logger) enabled(level int) bool {
+	return level >= l.GetLevel() || l.vmodule.Load() != nil
+}
+
+// allow reports whether a message at level from frame is logged,
+// ok is false if the caller is unknown. format keys the sampling of
+// messages without caller.
+func (l *Logger) allow(level int, frame runtime.Frame, ok bool, format string) bool {
+	min := l.GetLevel()
+	if vm := l.vmodule.Load(); vm != nil && ok {
+		if v, ok := vm.level(&frame); ok {
+			min = v
 		}
+	}
+	if level < min {
+		return false
+	}
 
-		msg = "[" + file + ":" + strconv.FormatInt(int64(line), 10) + "] " + msg
+	if s := l.sampler.Load(); s != nil && !isPanicOrFatal(level) {
+		if !s.sample(level, &frame, ok, format) {
+			l.stats.sampled.Add(1)
+			return false
+		}
 	}
+	return true
+}
+
+func (l *Logger) newRecord(level int, msg string) *Record {
+	r := recordPool.Get().(*Record)
+	r.Time = time.Now()
+	r.Level = level
+	r.Message = msg
+	r.Fields = l.fields
+	r.Name = l.name
 
-	msg = levelPrefix[level] + msg
+	return r
+}
 
-	if l.msgChanLen > 0 {
-		lm := logMsgPool.Get().(*logMsg)
-		lm.level = level
-		lm.msg = msg
-		lm.when = when
-		l.msgChan <- lm
+// output hands r to the adapters, r must not be used afterwards.
+func (l *Logger) output(r *Record) error {
+	level := r.Level
+
+	// the record is recycled once written, so render the panic value first
+	var panicMsg string
+	if level == LevelPanic {
+		panicMsg = r.legacyMsg()
+	}
+
+	l.stats.countLevel(level)
+	if l.closed.Load() {
+		writeFallback(r)
+		putRecord(r)
+	} else if l.msgChanLen.Load() > 0 {
+		l.enqueue(r)
+		l.stats.countQueue(int64(len(l.msgChan)))
 	} else {
-		l.writeToLoggers(when, msg, level)
+		l.writeToLoggers(r)
+		putRecord(r)
 	}
 
 	switch level {
 	case LevelPanic:
-		panic(msg)
+		l.Flush()
+		panic(panicMsg)
 	case LevelFatal:
-		os.Exit(1)
+		l.fatalExit()
 	}
 	return nil
 }
 
-func (l *Logger) writeToLoggers(when time.Time, msg string, level int) {
-	for _, v := range l.outputs {
-		err := v.WriteMsg(when, msg, level)
-		if err != nil {
-			fmt.Fprintf(os.Stderr,
-				"logx: write to adapter(%s) error:%v\n", v.name, err)
-		}
+func (l *Logger) writeToLoggers(r *Record) {
+	l.runHooks(r)
+
+	for _, v := range l.getOutputs() {
+		l.writeOutput(v, r)
 	}
 }
 
-func (l *Logger) flush() {
-	if l.msgChanLen > 0 {
-		for {
-			if len(l.msgChan) > 0 {
-				lm := <-l.msgChan
-				l.writeToLoggers(lm.when, lm.msg, lm.level)
-				logMsgPool.Put(lm)
-				continue
-			}
-			break
-		}
-	}
-	for _, l := range l.outputs {
-		l.Flush()
+// writeOutput writes r to v, or to its queue, if its level passes.
+func (l *Logger) writeOutput(v *nameLogger, r *Record) {
+	if q := v.queue.Load(); q != nil {
+		q.put(r)
+		return
 	}
+
+	v.write(r)
 }
 
-func (l *Logger) Flush() {
-	if l.msgChanLen > 0 {
-		l.signalChan <- "flush"
-		l.wg.Wait()
-		l.wg.Add(1)
+// write writes r to nl if its level passes.
+func (nl *nameLogger) write(r *Record) {
+	if r.Level < int(nl.level.Load()) {
+		return
+	}
+
+	err := nl.WriteRecord(r)
+	if err == nil {
+		nl.messages.Add(1)
 	} else {
-		l.flush()
+		nl.errors.Add(1)
+		nl.reportError(OpWrite, err)
 	}
 }
 
-func (l *Logger) Close() {
-	if l.msgChanLen > 0 {
-		l.signalChan <- "close"
-		l.wg.Wait()
-		close(l.msgChan)
-	} else {
-		l.flush()
-		for _, v := range l.outputs {
-			v.Destroy()
+func (l *Logger) flush() error {
+	if l.msgChanLen.Load() > 0 {
+		// OverflowDropOldest may take the queued records too, so never wait
+		b := &batcher{}
+		for l.takeQueued(b) {
+			l.writeBatch(b)
 		}
+		l.writeDropped()
+	}
 
-		l.outputs = nil
+	var errs []error
+	for _, v := range l.getOutputs() {
+		errs = append(errs, v.sync())
 	}
-	close(l.signalChan)
+	return errors.Join(errs...)
+}
+
+// Flush writes the queued records and flushes the outputs, see FlushContext.
+func (l *Logger) Flush() {
+	l.FlushContext(context.Background())
+}
+
+// Close flushes and closes the outputs, see Shutdown.
+func (l *Logger) Close() {
+	l.Shutdown(context.Background())
 }
 
 func (l *Logger) Reset() {
-	l.flush()
+	l.Flush()
+	l.destroyOutputs()
+}
 
-	for _, v := range l.outputs {
-		v.Destroy()
+// destroyOutputs removes all outputs and destroys them.
+func (l *Logger) destroyOutputs() error {
+	l.lock.Lock()
+	old := l.getOutputs()
+	l.setOutputs(nil)
+	l.lock.Unlock()
+
+	var errs []error
+	for _, v := range old {
+		errs = append(errs, v.close())
+	}
+	return errors.Join(errs...)
+}
+
+// Named returns a child logger with name appended to the name of l,
+// like: "db" then "db.pool".
+func (l *Logger) Named(name string) *Logger {
+	child := l.With()
+	if child.name != "" && name != "" {
+		child.name += "." + name
+	} else if name != "" {
+		child.name = name
 	}
 
-	l.outputs = nil
+	return child
 }
 
 func (l *Logger) SetLevel(level int) {
-	l.level = level
+	l.level.Store(int64(level))
 }
 
 func (l *Logger) GetLevel() int {
-	return l.level
+	return int(l.level.Load())
 }
 
+// SetFuncCallDepth(0) disables caller reporting. Any other depth enables it,
+// the caller is found by skipping the frames of logx and of Helper functions,
+// so the depth itself is no longer used.
 func (l *Logger) SetFuncCallDepth(depth int) {
-	l.funcCallDepth = depth
+	l.funcCallDepth.Store(int64(depth))
 }
 
 func (l *Logger) GetFuncCallDepth() int {
-	return l.funcCallDepth
+	return int(l.funcCallDepth.Load())
 }
 
 // default is false
 func (l *Logger) SetShortfile(b bool) {
-	l.isShortfile = b
+	l.isShortfile.Store(b)
 }
 
 func (l *Logger) GetShortfile() bool {
-	return l.isShortfile
+	return l.isShortfile.Load()
 }
@@ -1,71 +1,117 @@
 package logx
 
 import (
+	"errors"
 	"sync"
-	"time"
 )
 
 const (
 	defaultAsyncMsgLen = 1e3
 )
 
-type logMsg struct {
-	level int
-	msg   string
-	when  time.Time
-}
-
-var logMsgPool = &sync.Pool{
+var recordPool = &sync.Pool{
 	New: func() interface{} {
-		return &logMsg{}
+		return &Record{}
 	},
 }
 
+func putRecord(r *Record) {
+	r.reset()
+	recordPool.Put(r)
+}
+
+// asyncSignal asks the async worker to "flush" or "close",
+// done is closed once it is handled.
+type asyncSignal struct {
+	op   string
+	done chan struct{}
+	err  *error // set before done is closed
+}
+
 func (l *Logger) Async(length ...int64) {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 
-	if l.msgChanLen > 0 {
+	if l.msgChanLen.Load() > 0 {
 		return
 	}
 
-	l.msgChanLen = append(length, defaultAsyncMsgLen)[0]
+	msgChanLen := append(length, defaultAsyncMsgLen)[0]
 
-	if l.msgChanLen <= 0 {
-		l.msgChanLen = defaultAsyncMsgLen
+	if msgChanLen <= 0 {
+		msgChanLen = defaultAsyncMsgLen
 	}
 
-	l.msgChan = make(chan *logMsg, l.msgChanLen)
-	l.wg.Add(1)
+	l.msgChan = make(chan *Record, msgChanLen)
+	l.asyncDone = make(chan struct{})
 
 	go l.startLogger()
+
+	// publish msgChan to writeMsg
+	l.msgChanLen.Store(msgChanLen)
+}
+
+// signal sends op to the async worker and waits until it is handled,
+// it returns at once if the worker is gone.
+func (l *Logger) signal(op string) error {
+	return sendSignal(l.signalChan, l.asyncDone, op)
+}
+
+// sendSignal sends op to signals and waits until it is handled, or until
+// done is closed by the exiting worker.
+func sendSignal(signals chan asyncSignal, done <-chan struct{}, op string) error {
+	var err error
+	handled := make(chan struct{})
+	select {
+	case signals <- asyncSignal{op: op, done: handled, err: &err}:
+	case <-done:
+		return nil
+	}
+
+	select {
+	case <-handled:
+	case <-done:
+	}
+	return err
 }
 
 func (l *Logger) startLogger() {
-	gameOver := false
+	defer close(l.asyncDone)
 
+	b := &batcher{}
 	for {
 		select {
-		case lm := <-l.msgChan:
-			l.writeToLoggers(lm.when, lm.msg, lm.level)
-			logMsgPool.Put(lm)
-		case sg := <-l.signalChan:
-			// Now should only send "flush" or "close" to l.signalChan
-			l.flush()
+		case r := <-l.msgChan:
+			b.records = append(b.records, r)
+			sg := l.collect(b)
+			l.writeBatch(b)
 
-			if sg == "close" {
-				for _, l := range l.outputs {
-					l.Destroy()
-				}
-
-				l.outputs = nil
-				gameOver = true
+			// the pressure is gone
+			if len(l.msgChan) == 0 {
+				l.writeDropped()
 			}
 
-			l.wg.Done()
-		}
-		if gameOver {
-			break
+			if sg != nil && l.handleSignal(*sg) {
+				return
+			}
+		case sg := <-l.signalChan:
+			if l.handleSignal(sg) {
+				return
+			}
 		}
 	}
 }
+
+// handleSignal flushes, and destroys the outputs on "close",
+// it reports whether the worker must exit.
+func (l *Logger) handleSignal(sg asyncSignal) bool {
+	// Now should only send "flush" or "close" to l.signalChan
+	err := l.flush()
+	if sg.op == "close" {
+		err = errors.Join(err, l.destroyOutputs())
+	}
+
+	*sg.err = err
+	close(sg.done)
+	return sg.op == "close"
+}
@@ -0,0 +1,144 @@
+package logx
+
+import (
+	"time"
+)
+
+const defaultBatchSize = 128
+
+// BatchStorer is implemented by adapters which write several records at once,
+// the async worker hands them its batches, see SetBatch.
+// The records are only valid during WriteBatch, they must not be retained.
+type BatchStorer interface {
+	WriteBatch(records []Record) error
+}
+
+// SetBatch makes the async worker collect up to size records, waiting at most
+// wait for them, and deliver them together to the adapters implementing
+// BatchStorer. Default is 128 records without waiting, which only batches
+// the records already queued. size <= 1 disables batching.
+func (l *Logger) SetBatch(size int, wait time.Duration) {
+	if size < 1 {
+		size = 1
+	}
+
+	l.batchSize.Store(int64(size))
+	l.batchWait.Store(int64(wait))
+}
+
+// batcher holds the buffers of the async worker.
+type batcher struct {
+	records []*Record
+	values  []Record // handed to WriteBatch
+}
+
+// takeQueued adds the records queued in ch to b.records without waiting, up
+// to size. It reports whether b.records is not empty.
+func takeQueued(ch chan *Record, b *batcher, size int) bool {
+	for len(b.records) < size {
+		select {
+		case r := <-ch:
+			b.records = append(b.records, r)
+		default:
+			return len(b.records) > 0
+		}
+	}
+
+	return true
+}
+
+// collect adds the records of ch to b.records, up to size and waiting at
+// most wait. It returns a signal received meanwhile, which must be handled
+// after the batch.
+func collect(ch chan *Record, signals chan asyncSignal, b *batcher, size int, wait time.Duration) *asyncSignal {
+	takeQueued(ch, b, size)
+	if len(b.records) >= size || wait <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	for len(b.records) < size {
+		select {
+		case r := <-ch:
+			b.records = append(b.records, r)
+		case sg := <-signals:
+			return &sg
+		case <-t.C:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (l *Logger) takeQueued(b *batcher) bool {
+	return takeQueued(l.msgChan, b, int(l.batchSize.Load()))
+}
+
+func (l *Logger) collect(b *batcher) *asyncSignal {
+	return collect(l.msgChan, l.signalChan, b,
+		int(l.batchSize.Load()), time.Duration(l.batchWait.Load()))
+}
+
+// writeBatch writes and recycles b.records.
+func (l *Logger) writeBatch(b *batcher) {
+	for _, r := range b.records {
+		l.runHooks(r)
+	}
+
+	for _, v := range l.getOutputs() {
+		if q := v.queue.Load(); q != nil {
+			for _, r := range b.records {
+				q.put(r)
+			}
+			continue
+		}
+		v.writeBatch(b)
+	}
+
+	b.recycle()
+}
+
+// writeBatch writes b.records to nl, at once if it is a BatchStorer.
+func (nl *nameLogger) writeBatch(b *batcher) {
+	bs, ok := nl.RecordStorer.(BatchStorer)
+	// a dedup window needs to see every record
+	if !ok || len(b.records) == 1 || nl.dedup.Load() != nil {
+		for _, r := range b.records {
+			nl.write(r)
+		}
+		return
+	}
+
+	level := int(nl.level.Load())
+	b.values = b.values[:0]
+	for _, r := range b.records {
+		if r.Level >= level {
+			b.values = append(b.values, *r)
+		}
+	}
+	if len(b.values) == 0 {
+		return
+	}
+
+	if err := bs.WriteBatch(b.values); err == nil {
+		nl.messages.Add(int64(len(b.values)))
+	} else {
+		nl.errors.Add(1)
+		nl.reportError(OpWrite, err)
+	}
+}
+
+// recycle puts b.records back to the pool.
+func (b *batcher) recycle() {
+	for i, r := range b.records {
+		putRecord(r)
+		b.records[i] = nil
+	}
+	b.records = b.records[:0]
+	// drop the references to the strings of the records
+	for i := range b.values {
+		b.values[i] = Record{}
+	}
+}
@@ -0,0 +1,94 @@
+package logx
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchStorer struct {
+	recordStorer
+	lock    sync.Mutex
+	batches []int
+}
+
+func (s *batchStorer) WriteBatch(records []Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.batches = append(s.batches, len(records))
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func TestBatch(t *testing.T) {
+	s := &batchStorer{}
+	log := NewLogger()
+	log.Async(100)
+	log.SetBatch(10, time.Second)
+	log.AddRecordStorer("batch", s, `{"level":"info"}`)
+
+	for i := 0; i < 25; i++ {
+		log.Debug("skipped")
+		log.Info(i)
+	}
+	log.Flush()
+	if n := log.Stats().Adapters["batch"].Messages; n != 25 {
+		t.Fatal("got", n, "messages not 25")
+	}
+	log.Close()
+
+	if len(s.records) != 25 {
+		t.Fatal("got", len(s.records), "records not 25")
+	}
+	for i, r := range s.records {
+		if r.Message != strconv.Itoa(i) {
+			t.Fatal("unexpected order", i, r.Message)
+		}
+	}
+	// batches of 10 records, the debug ones are filtered out of them
+	if len(s.batches) < 5 {
+		t.Fatal("unexpected batches", s.batches)
+	}
+}
+
+func TestFileWriteBatch(t *testing.T) {
+	dir := t.TempDir()
+	w := newAdapterFile().(*fileWriter)
+	err := w.Init(`{"daily":false,"maxline":3,"filename":"` +
+		filepath.ToSlash(filepath.Join(dir, "batch.log")) + `"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteRecord(&Record{Time: time.Now(), Message: "0"})
+
+	records := make([]Record, 7)
+	for i := range records {
+		records[i] = Record{Time: time.Now(), Level: LevelInfo, Message: strconv.Itoa(i + 1)}
+	}
+	if err := w.WriteBatch(records); err != nil {
+		t.Fatal(err)
+	}
+	w.Destroy()
+
+	entries, _ := os.ReadDir(dir)
+	lines := []int{}
+	for _, v := range entries {
+		b, _ := os.ReadFile(filepath.Join(dir, v.Name()))
+		lines = append(lines, bytes.Count(b, []byte{'\n'}))
+	}
+	sort.Ints(lines)
+
+	// 8 records, at most 3 per file
+	if len(lines) != 3 || lines[0] != 2 || lines[1] != 3 || lines[2] != 3 {
+		t.Fatal("unexpected lines per file", lines)
+	}
+	if w.rotations.Load() != 2 {
+		t.Fatal("got", w.rotations.Load(), "rotations not 2")
+	}
+}
@@ -0,0 +1,79 @@
+package logx
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// run with: go test -run NONE -bench . -benchmem
+
+// newBenchLogger returns a logger writing to adapter with the given format,
+// the console writes to io.Discard.
+func newBenchLogger(b *testing.B, adapter, format string) *Logger {
+	l := NewLogger()
+	l.SetShortfile(true)
+
+	cfg := `{"format":"` + format + `"}`
+	switch adapter {
+	case AdapterFile:
+		cfg = `{"format":"` + format + `","daily":false,"filename":"` +
+			filepath.ToSlash(filepath.Join(b.TempDir(), "bench.log")) + `"}`
+	case AdapterMultifile:
+		cfg = `{"format":"` + format + `","daily":false,"full":true,"separate":["info"],"filename":"` +
+			filepath.ToSlash(filepath.Join(b.TempDir(), "bench.log")) + `"}`
+	}
+	if err := l.AddLogger(adapter, cfg); err != nil {
+		b.Fatal(err)
+	}
+
+	if c, ok := l.getOutputs()[0].RecordStorer.(*consoleWriter); ok {
+		c.lg = newLogWriter(io.Discard)
+	}
+
+	return l
+}
+
+func benchmarkLogger(b *testing.B, async bool) {
+	for _, adapter := range []string{AdapterConsole, AdapterFile, AdapterMultifile} {
+		for _, format := range []string{FormatText, FormatJSON, FormatLogfmt} {
+			b.Run(adapter+"/"+format, func(b *testing.B) {
+				l := newBenchLogger(b, adapter, format)
+				if async {
+					l.Async(1000)
+				}
+				child := l.With("request_id", 42, "user", "foo")
+				err := errors.New("failed")
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					child.Infof("request %d done", i)
+					l.Info("info")
+					l.ErrInfo(err)
+				}
+				b.StopTimer()
+				l.Close()
+			})
+		}
+	}
+}
+
+func BenchmarkLoggerSync(b *testing.B) {
+	benchmarkLogger(b, false)
+}
+
+func BenchmarkLoggerAsync(b *testing.B) {
+	benchmarkLogger(b, true)
+}
+
+func BenchmarkLoggerDisabled(b *testing.B) {
+	l := newBenchLogger(b, AdapterConsole, FormatText)
+	l.SetLevel(LevelError)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("info", i)
+	}
+}
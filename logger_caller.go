@@ -0,0 +1,181 @@
+package logx
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	maxCallerDepth = 32
+	// maxCallerCache bounds the caller cache, call sites beyond it are resolved every time
+	maxCallerCache = 1 << 14
+)
+
+// logxFuncPrefix is the prefix of the functions of this package,
+// like "github.com/meilihao/logx."
+var logxFuncPrefix = strings.TrimSuffix(
+	runtime.FuncForPC(reflect.ValueOf(NewLogger).Pointer()).Name(), "NewLogger")
+
+var (
+	helperFuncs sync.Map // function name -> struct{}
+	helperPCs   sync.Map // pc of Helper callers -> struct{}, avoids resolving them again
+)
+
+// Helper marks the calling function as a logging helper, like testing.T.Helper.
+// Its frames are skipped when the caller and the stack of a record are resolved,
+// so wrappers around logx report the line which called them.
+func Helper() {
+	var pcs [1]uintptr
+	if runtime.Callers(2, pcs[:]) == 0 {
+		return
+	}
+	if _, ok := helperPCs.Load(pcs[0]); ok {
+		return
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pcs[0]}).Next()
+	if _, loaded := helperFuncs.LoadOrStore(frame.Function, struct{}{}); !loaded {
+		resetCallerCache()
+	}
+	helperPCs.Store(pcs[0], struct{}{})
+}
+
+// skipFrame reports whether f belongs to logx (tests excluded) or to a Helper.
+func skipFrame(f *runtime.Frame) bool {
+	if strings.HasPrefix(f.Function, logxFuncPrefix) && !strings.HasSuffix(f.File, "_test.go") {
+		return true
+	}
+
+	_, ok := helperFuncs.Load(f.Function)
+	return ok
+}
+
+// callerEntry is the resolved caller of a pc, see lookupCaller.
+type callerEntry struct {
+	frame runtime.Frame
+	ok    bool // false when all frames of the pc are skipped by skipFrame
+}
+
+// callerCacheMap maps a pc to its callerEntry.
+type callerCacheMap struct {
+	m sync.Map
+	n atomic.Int64 // entries, bounded by maxCallerCache
+}
+
+// callerCache is replaced by resetCallerCache, the entries of the old one
+// may miss a Helper.
+var callerCache atomic.Pointer[callerCacheMap]
+
+func init() {
+	callerCache.Store(&callerCacheMap{})
+}
+
+// lookupCaller returns the first frame of pc which is not skipped by skipFrame.
+// Expanding a pc into frames allocates, so the result is cached per pc.
+func lookupCaller(pc uintptr) callerEntry {
+	// loaded first, a Helper registered afterwards replaces c
+	c := callerCache.Load()
+	if v, ok := c.m.Load(pc); ok {
+		return v.(callerEntry)
+	}
+
+	var e callerEntry
+	frames := runtime.CallersFrames([]uintptr{pc})
+	for {
+		f, more := frames.Next()
+		if !skipFrame(&f) {
+			e = callerEntry{frame: f, ok: true}
+			break
+		}
+		if !more {
+			break
+		}
+	}
+
+	if c.n.Load() < maxCallerCache {
+		if _, loaded := c.m.LoadOrStore(pc, e); !loaded {
+			c.n.Add(1)
+		}
+	}
+
+	return e
+}
+
+func resetCallerCache() {
+	callerCache.Store(&callerCacheMap{})
+}
+
+// callerFrame returns the first frame of the calling goroutine which is
+// skipped neither by skipFrame nor by skipMore.
+func callerFrame(skipMore func(f *runtime.Frame) bool) (runtime.Frame, bool) {
+	var pcs [maxCallerDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+
+	for i, pc := range pcs[:n] {
+		e := lookupCaller(pc)
+		if !e.ok {
+			continue
+		}
+		if skipMore == nil {
+			return e.frame, true
+		}
+
+		// declared here, so that only this branch pays for f escaping into skipMore
+		f := e.frame
+		if !skipMore(&f) {
+			return f, true
+		}
+
+		// skipMore may accept a frame inlined into the same pc, expand from here
+		return callerFrameSlow(append([]uintptr(nil), pcs[i:n]...), skipMore)
+	}
+
+	return runtime.Frame{}, false
+}
+
+func callerFrameSlow(pcs []uintptr, skipMore func(f *runtime.Frame) bool) (runtime.Frame, bool) {
+	frames := runtime.CallersFrames(pcs)
+
+	for {
+		f, more := frames.Next()
+		if !skipFrame(&f) && !skipMore(&f) {
+			return f, true
+		}
+		if !more {
+			break
+		}
+	}
+
+	return runtime.Frame{}, false
+}
+
+// caller resolves the caller when it is reported or needed by vmodule or sampling.
+func (l *Logger) caller(skipMore func(f *runtime.Frame) bool) (runtime.Frame, bool) {
+	if l.GetFuncCallDepth() <= 0 && l.vmodule.Load() == nil && l.sampler.Load() == nil {
+		return runtime.Frame{}, false
+	}
+
+	return callerFrame(skipMore)
+}
+
+// SetCallerFunc makes records carry the function name of the caller, default is false.
+func (l *Logger) SetCallerFunc(b bool) {
+	l.callerFunc.Store(b)
+}
+
+func (l *Logger) GetCallerFunc() bool {
+	return l.callerFunc.Load()
+}
+
+// shortFuncName trims the package path of name,
+// like "github.com/a/b.(*T).F" -> "b.(*T).F".
+func shortFuncName(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[i+1:]
+	}
+
+	return name
+}
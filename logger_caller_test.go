@@ -0,0 +1,57 @@
+package logx
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func infoHelper(l *Logger, msg string) {
+	Helper()
+	l.Info(msg)
+}
+
+func infoNoHelper(l *Logger, msg string) {
+	l.Info(msg)
+}
+
+func TestCaller(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.SetShortfile(true)
+	l.SetCallerFunc(true)
+	l.AddRecordStorer("record", s)
+
+	old := defaultLogger
+	SetOutput(l)
+	defer SetOutput(old)
+
+	_, _, line, _ := runtime.Caller(0)
+	l.Info("instance")
+	With("k", "v").Info("child")
+	Info("package")
+	infoHelper(l, "helper")
+	infoNoHelper(l, "no helper")
+	l.StdLogger(LevelInfo).Print("std")
+
+	// 15 is the line of l.Info in infoNoHelper
+	lines := []int{line + 1, line + 2, line + 3, line + 4, 15, line + 6}
+	if len(s.records) != len(lines) {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+	for i, want := range lines {
+		r := s.records[i]
+		if r.File != "logger_caller_test.go" || r.Line != want {
+			t.Fatal(r.Message, "reported", r.File, r.Line, "not", want)
+		}
+	}
+	if filepath.Ext(s.records[0].Func) != ".TestCaller" || filepath.Ext(s.records[4].Func) != ".infoNoHelper" {
+		t.Fatal("unexpected func", s.records[0].Func, s.records[4].Func)
+	}
+
+	l.SetFuncCallDepth(0)
+	l.Info("no caller")
+	if r := s.records[len(s.records)-1]; r.File != "" || r.Line != 0 {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
@@ -0,0 +1,256 @@
+package logx
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor pulls fields out of a context.Context for the XxxCtx methods.
+type ContextExtractor func(ctx context.Context) []Field
+
+var (
+	ctxExtractorsLock sync.Mutex // serializes RegisterContextExtractor
+	// copy on write, readers never lock
+	ctxExtractors atomic.Pointer[[]ContextExtractor]
+)
+
+func init() {
+	ctxExtractors.Store(&[]ContextExtractor{fieldsFromContext, traceFromContext})
+}
+
+// RegisterContextExtractor adds fn to the extractors run by every XxxCtx call.
+// Fields from ContextWithFields and ContextWithTraceparent are extracted by default.
+func RegisterContextExtractor(fn ContextExtractor) {
+	if fn == nil {
+		panic("logx: invalid ContextExtractor")
+	}
+
+	ctxExtractorsLock.Lock()
+	old := contextExtractors()
+	extractors := make([]ContextExtractor, 0, len(old)+1)
+	extractors = append(extractors, old...)
+	extractors = append(extractors, fn)
+	ctxExtractors.Store(&extractors)
+	ctxExtractorsLock.Unlock()
+}
+
+func contextExtractors() []ContextExtractor {
+	return *ctxExtractors.Load()
+}
+
+type ctxKey int
+
+const (
+	ctxKeyFields ctxKey = iota
+	ctxKeyTrace
+)
+
+// ContextWithFields returns a copy of ctx carrying kv in addition to the
+// fields already stored in ctx.
+func ContextWithFields(ctx context.Context, kv ...interface{}) context.Context {
+	old, _ := ctx.Value(ctxKeyFields).([]Field)
+
+	fields := make([]Field, 0, len(old)+len(kv)/2+1)
+	fields = append(fields, old...)
+	fields = appendFields(fields, kv)
+
+	return context.WithValue(ctx, ctxKeyFields, fields)
+}
+
+func fieldsFromContext(ctx context.Context) []Field {
+	fields, _ := ctx.Value(ctxKeyFields).([]Field)
+	return fields
+}
+
+type traceContext struct {
+	traceID, spanID string
+}
+
+// ContextWithTraceparent returns a copy of ctx carrying the trace and span id
+// of a W3C traceparent header, like:
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+// They are logged as trace_id and span_id. An invalid header is ignored.
+func ContextWithTraceparent(ctx context.Context, traceparent string) context.Context {
+	tc, err := parseTraceparent(traceparent)
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ctxKeyTrace, tc)
+}
+
+func parseTraceparent(s string) (traceContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return traceContext{}, fmt.Errorf("logx: invalid traceparent %q", s)
+	}
+	// version 00 has exactly four parts
+	if parts[0] == "00" && len(parts) != 4 {
+		return traceContext{}, fmt.Errorf("logx: invalid traceparent %q", s)
+	}
+
+	for _, v := range parts[:4] {
+		if _, err := hex.DecodeString(v); err != nil || v != strings.ToLower(v) {
+			return traceContext{}, fmt.Errorf("logx: invalid traceparent %q", s)
+		}
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return traceContext{}, fmt.Errorf("logx: invalid traceparent %q", s)
+	}
+
+	return traceContext{traceID: parts[1], spanID: parts[2]}, nil
+}
+
+func traceFromContext(ctx context.Context) []Field {
+	tc, ok := ctx.Value(ctxKeyTrace).(traceContext)
+	if !ok {
+		return nil
+	}
+
+	return []Field{{Key: "trace_id", Value: tc.traceID}, {Key: "span_id", Value: tc.spanID}}
+}
+
+// contextFields returns the fields of l followed by the fields extracted from ctx.
+func (l *Logger) contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return l.fields
+	}
+
+	fields := l.fields
+	owned := false
+	for _, fn := range contextExtractors() {
+		v := fn(ctx)
+		if len(v) == 0 {
+			continue
+		}
+
+		if !owned {
+			fields = append(make([]Field, 0, len(l.fields)+len(v)), l.fields...)
+			owned = true
+		}
+		fields = append(fields, v...)
+	}
+
+	return fields
+}
+
+func (l *Logger) writeMsgCtx(ctx context.Context, level int, msg string, v ...interface{}) error {
+	if len(v) == 0 {
+		panic("logx: Empty Output")
+	}
+
+	frame, ok := l.caller(nil)
+	if !l.allow(level, frame, ok, msg) {
+		return nil
+	}
+
+	r := l.newRecord(level, sprintf(msg, v))
+	r.Fields = l.contextFields(ctx)
+	if ok {
+		l.fillCaller(r, &frame)
+	}
+	l.fillStack(r)
+
+	return l.output(r)
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelDebug, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelInfo) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelInfo, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelWarn, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelError, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) PanicCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelPanic) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelPanic, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, v ...interface{}) {
+	if !l.enabled(LevelFatal) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelFatal, generateFmtStr(len(v)), v...)
+}
+
+func (l *Logger) DebugfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelDebug) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelDebug, format, v...)
+}
+
+func (l *Logger) InfofCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelInfo) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelInfo, format, v...)
+}
+
+func (l *Logger) WarnfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelWarn) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelWarn, format, v...)
+}
+
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelError, format, v...)
+}
+
+func (l *Logger) PanicfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelPanic) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelPanic, format, v...)
+}
+
+func (l *Logger) FatalfCtx(ctx context.Context, format string, v ...interface{}) {
+	if !l.enabled(LevelFatal) {
+		return
+	}
+
+	l.writeMsgCtx(ctx, LevelFatal, format, v...)
+}
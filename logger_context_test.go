@@ -0,0 +1,64 @@
+package logx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+
+	ctx := ContextWithFields(context.Background(), "request_id", 1)
+	ctx = ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	l.With("app", "x").InfofCtx(ctx, "%d", 1)
+	l.InfoCtx(ContextWithTraceparent(context.Background(), "00-00000000000000000000000000000000-00f067aa0ba902b7-01"), "invalid")
+
+	if len(s.records) != 2 {
+		t.Fatal("got", len(s.records), "records not 2")
+	}
+
+	want := []Field{
+		{"app", "x"},
+		{"request_id", 1},
+		{"trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"span_id", "00f067aa0ba902b7"},
+	}
+	fields := s.records[0].Fields
+	if len(fields) != len(want) {
+		t.Fatalf("unexpected fields %+v", fields)
+	}
+	for i, f := range want {
+		if fields[i] != f {
+			t.Fatalf("unexpected fields %+v", fields)
+		}
+	}
+	if len(s.records[1].Fields) != 0 {
+		t.Fatalf("unexpected fields %+v", s.records[1].Fields)
+	}
+}
+
+func TestContextExtractor(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+
+	// the extractors are global, keep -count runs independent
+	old := ctxExtractors.Load()
+	t.Cleanup(func() { ctxExtractors.Store(old) })
+
+	type tenantKey struct{}
+	RegisterContextExtractor(func(ctx context.Context) []Field {
+		if v, ok := ctx.Value(tenantKey{}).(string); ok {
+			return []Field{{Key: "tenant", Value: v}}
+		}
+		return nil
+	})
+
+	l.WarnCtx(context.WithValue(context.Background(), tenantKey{}, "t1"), "warn")
+
+	if len(s.records) != 1 || len(s.records[0].Fields) != 1 || s.records[0].Fields[0].Value != "t1" {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
@@ -0,0 +1,150 @@
+package logx
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dedup collapses identical consecutive records of an adapter, like syslog:
+// repeats are dropped and counted, and "previous message repeated N times"
+// is written when a different record arrives or window expires.
+type dedup struct {
+	storer RecordStorer
+	window time.Duration
+	report func(op string, err error)
+
+	lock sync.Mutex // also serializes the writes to storer
+	last struct {
+		level           int
+		file, msg, name string
+		line            int
+		valid           bool
+	}
+	repeated int
+	timer    *time.Timer // runs while repeated > 0
+	timerGen int         // tells a stale timer from the running one
+}
+
+func newDedup(storer RecordStorer, window time.Duration, report func(op string, err error)) *dedup {
+	return &dedup{storer: storer, window: window, report: report}
+}
+
+// WriteRecord writes r unless it repeats the previous record.
+func (d *dedup) WriteRecord(r *Record) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.last.valid && d.last.level == r.Level && d.last.line == r.Line &&
+		d.last.file == r.File && d.last.msg == r.Message && d.last.name == r.Name {
+		d.repeated++
+		if d.timer == nil {
+			gen := d.timerGen
+			d.timer = time.AfterFunc(d.window, func() { d.expire(gen) })
+		}
+		return nil
+	}
+
+	if err := d.writeRepeated(); err != nil {
+		d.report(OpWrite, err)
+	}
+	d.last.level, d.last.line = r.Level, r.Line
+	d.last.file, d.last.msg, d.last.name = r.File, r.Message, r.Name
+	d.last.valid = true
+
+	return d.storer.WriteRecord(r)
+}
+
+func (d *dedup) expire(gen int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if gen != d.timerGen {
+		return
+	}
+
+	// the run continues, the next repeats start a new count
+	if err := d.writeRepeated(); err != nil {
+		d.report(OpWrite, err)
+	}
+}
+
+// flush writes the pending count.
+func (d *dedup) flush() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err := d.writeRepeated(); err != nil {
+		d.report(OpWrite, err)
+	}
+}
+
+// writeRepeated writes the count of the dropped repeats, d.lock must be held.
+func (d *dedup) writeRepeated() error {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+		d.timerGen++
+	}
+	if d.repeated == 0 {
+		return nil
+	}
+
+	r := recordPool.Get().(*Record)
+	r.Time = time.Now()
+	r.Level = d.last.level
+	r.File, r.Line = d.last.file, d.last.line
+	r.Name = d.last.name
+	if d.repeated == 1 {
+		r.Message = "previous message repeated 1 time"
+	} else {
+		r.Message = "previous message repeated " + strconv.Itoa(d.repeated) + " times"
+	}
+	d.repeated = 0
+
+	err := d.storer.WriteRecord(r)
+	putRecord(r)
+	return err
+}
+
+// SetAdapterDedup collapses identical consecutive records (same level, caller
+// and message) written to the adapters named adapterName, within window.
+// window <= 0 writes every record. It is the same as the "dedup" config, like:
+// {"dedup":"10s"}
+func (l *Logger) SetAdapterDedup(adapterName string, window time.Duration) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	found := false
+	for _, v := range l.getOutputs() {
+		if v.name == adapterName {
+			v.setDedup(window)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
+	}
+
+	return nil
+}
+
+func (nl *nameLogger) setDedup(window time.Duration) {
+	var d *dedup
+	if window > 0 {
+		d = newDedup(nl.RecordStorer, window, nl.reportError)
+	}
+
+	if old := nl.dedup.Swap(d); old != nil {
+		old.flush()
+	}
+}
+
+func (nl *nameLogger) WriteRecord(r *Record) error {
+	if d := nl.dedup.Load(); d != nil {
+		return d.WriteRecord(r)
+	}
+
+	return nl.RecordStorer.WriteRecord(r)
+}
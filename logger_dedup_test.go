@@ -0,0 +1,67 @@
+package logx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedup(t *testing.T) {
+	s := &recordStorer{}
+	all := &recordStorer{}
+	log := NewLogger()
+	log.AddRecordStorer("dedup", s, `{"dedup":"1h"}`)
+	log.AddRecordStorer("all", all)
+
+	for i := 0; i < 5; i++ {
+		log.Warn("disk full")
+	}
+	for i := 0; i < 2; i++ {
+		log.Warn("disk ok")
+	}
+	log.Flush()
+
+	want := []string{"disk full", "previous message repeated 4 times", "disk ok", "previous message repeated 1 time"}
+	if len(s.records) != len(want) {
+		t.Fatal("got", len(s.records), "records not", len(want))
+	}
+	for i, v := range want {
+		if s.records[i].Message != v || s.records[i].Level != LevelWarn {
+			t.Fatalf("unexpected record %d %+v", i, s.records[i])
+		}
+	}
+	if s.records[1].File == "" || s.records[1].Line != s.records[0].Line {
+		t.Fatal("unexpected caller", s.records[1].File, s.records[1].Line)
+	}
+	if len(all.records) != 7 {
+		t.Fatal("got", len(all.records), "records not 7")
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.Async()
+	log.AddRecordStorer("dedup", s)
+	if err := log.SetAdapterDedup("dedup", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	tick := func() { log.Info("tick") }
+	for i := 0; i < 3; i++ {
+		tick()
+	}
+	// the window expires
+	time.Sleep(100 * time.Millisecond)
+	tick()
+	log.Close()
+
+	want := []string{"tick", "previous message repeated 2 times", "previous message repeated 1 time"}
+	if len(s.records) != len(want) {
+		t.Fatal("got", len(s.records), "records not", len(want))
+	}
+	for i, v := range want {
+		if s.records[i].Message != v {
+			t.Fatalf("unexpected record %d %+v", i, s.records[i])
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// the operations of AdapterError
+const (
+	OpInit      = "init"
+	OpWrite     = "write"
+	OpRotate    = "rotate"
+	OpRetention = "retention" // deleting old files, see "maxday"
+	OpHook      = "hook"
+)
+
+// errors.Is reports whether an AdapterError is of the operation, like:
+// errors.Is(err, logx.ErrRotation)
+var (
+	ErrInit      = errors.New("logx: init error")
+	ErrWrite     = errors.New("logx: write error")
+	ErrRotation  = errors.New("logx: rotation error")
+	ErrRetention = errors.New("logx: retention error")
+	ErrHook      = errors.New("logx: hook error")
+)
+
+var opErrors = map[string]error{
+	OpInit:      ErrInit,
+	OpWrite:     ErrWrite,
+	OpRotate:    ErrRotation,
+	OpRetention: ErrRetention,
+	OpHook:      ErrHook,
+}
+
+// AdapterError is the error handed to the ErrorHandler.
+type AdapterError struct {
+	Adapter string // empty for hooks
+	Op      string
+	Err     error
+	// Suppressed counts the errors of the same adapter and operation
+	// dropped by rate limiting since the previous one
+	Suppressed int
+}
+
+func (e *AdapterError) Error() string {
+	s := "logx: " + e.Op + " adapter(" + e.Adapter + ") error:" + e.Err.Error()
+	if e.Suppressed > 0 {
+		s += " (" + strconv.Itoa(e.Suppressed) + " similar errors suppressed)"
+	}
+	return s
+}
+
+func (e *AdapterError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AdapterError) Is(target error) bool {
+	return target != nil && opErrors[e.Op] == target
+}
+
+// ErrorReporter is implemented by adapters failing outside of WriteRecord,
+// like the rotation of the file adapter. The reporter is set before Init,
+// the errors reported through it reach the ErrorHandler.
+type ErrorReporter interface {
+	SetErrorReporter(report func(op string, err error))
+}
+
+// ErrorHandler receives the failures of the adapters and of the hooks,
+// err is an *AdapterError.
+type ErrorHandler func(adapter, op string, err error)
+
+// SetErrorHandler sets the handler of the failures of the adapters and of the
+// hooks, nil prints them to stderr, the default. At most 10 errors of an
+// adapter and operation are handled per second, the others are counted by
+// AdapterError.Suppressed. fn is called by the failing goroutine, which may
+// be the async worker, so it must not log to l.
+func (l *Logger) SetErrorHandler(fn ErrorHandler) {
+	if fn == nil {
+		l.errorHandler.Store(nil)
+		return
+	}
+
+	l.errorHandler.Store(&fn)
+}
+
+func (c *logCore) reportError(adapter, op string, err error) {
+	suppressed, ok := c.errorLimiter.allow(adapter, op, time.Now())
+	if !ok {
+		return
+	}
+
+	e := &AdapterError{Adapter: adapter, Op: op, Err: err, Suppressed: suppressed}
+	if p := c.errorHandler.Load(); p != nil {
+		(*p)(adapter, op, e)
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, e.Error())
+}
+
+func (nl *nameLogger) reportError(op string, err error) {
+	nl.core.reportError(nl.name, op, err)
+}
+
+const (
+	errorLimitBurst  = 10
+	errorLimitPeriod = time.Second
+)
+
+// errorLimiter limits the errors per adapter and operation.
+type errorLimiter struct {
+	lock    sync.Mutex
+	windows map[[2]string]*errorWindow
+}
+
+type errorWindow struct {
+	start      time.Time
+	n          int
+	suppressed int
+}
+
+// allow reports whether an error is handled, and how many were not since
+// the previous one.
+func (el *errorLimiter) allow(adapter, op string, now time.Time) (suppressed int, ok bool) {
+	el.lock.Lock()
+	defer el.lock.Unlock()
+
+	if el.windows == nil {
+		el.windows = map[[2]string]*errorWindow{}
+	}
+	key := [2]string{adapter, op}
+	w := el.windows[key]
+	if w == nil {
+		w = &errorWindow{start: now}
+		el.windows[key] = w
+	}
+
+	if now.Sub(w.start) >= errorLimitPeriod {
+		w.start, w.n = now, 0
+	}
+	if w.n >= errorLimitBurst {
+		w.suppressed++
+		return 0, false
+	}
+
+	w.n++
+	suppressed, w.suppressed = w.suppressed, 0
+	return suppressed, true
+}
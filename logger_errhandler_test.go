@@ -0,0 +1,59 @@
+package logx
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrorHandler(t *testing.T) {
+	var got []error
+	log := NewLogger()
+	log.SetErrorHandler(func(adapter, op string, err error) {
+		got = append(got, err)
+	})
+
+	if log.AddRecordStorer("record", &recordStorer{}, `{"level":"nope"}`) == nil {
+		t.Fatal("invalid config accepted")
+	}
+	log.AddRecordStorer("failing", &failingStorer{})
+	for i := 0; i < errorLimitBurst+5; i++ {
+		log.Info("lost")
+	}
+
+	if len(got) != errorLimitBurst+1 {
+		t.Fatalf("got %d errors, want %d", len(got), errorLimitBurst+1)
+	}
+	var e *AdapterError
+	if !errors.Is(got[0], ErrInit) || !errors.As(got[0], &e) || e.Adapter != "record" {
+		t.Errorf("got %v", got[0])
+	}
+	if !errors.Is(got[1], ErrWrite) || errors.Is(got[1], ErrRotation) || !errors.As(got[1], &e) ||
+		e.Adapter != "failing" || e.Op != OpWrite || e.Err.Error() != "disk full" {
+		t.Errorf("got %v", got[1])
+	}
+	if st := log.Stats(); st.Adapters["failing"].Errors != errorLimitBurst+5 {
+		t.Errorf("got %d write errors", st.Adapters["failing"].Errors)
+	}
+}
+
+func TestErrorLimiter(t *testing.T) {
+	el := &errorLimiter{}
+	now := time.Now()
+	for i := 0; i < errorLimitBurst; i++ {
+		if _, ok := el.allow("file", OpRotate, now); !ok {
+			t.Fatal("error", i, "suppressed")
+		}
+	}
+	if _, ok := el.allow("file", OpRotate, now); ok {
+		t.Fatal("burst exceeded")
+	}
+	if _, ok := el.allow("file", OpWrite, now); !ok {
+		t.Fatal("other operation suppressed")
+	}
+
+	suppressed, ok := el.allow("file", OpRotate, now.Add(errorLimitPeriod))
+	if !ok || suppressed != 1 {
+		t.Fatal("got", suppressed, ok)
+	}
+}
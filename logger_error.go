@@ -0,0 +1,140 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// maxErrorCauses bounds the causes collected from an error chain.
+const maxErrorCauses = 32
+
+// ErrorFielder is implemented by errors carrying structured data, which is
+// merged into the fields of the record logging them.
+type ErrorFielder interface {
+	LogFields() map[string]interface{}
+}
+
+// ErrorErr logs msg at LevelError with err and kv as fields, like:
+//
+//	log.ErrorErr(err, "save user", "user_id", id)
+//
+// The message is err.Error() when msg is empty.
+func (l *Logger) ErrorErr(err error, msg string, kv ...interface{}) {
+	if !l.enabled(LevelError) {
+		return
+	}
+
+	l.writeErr(LevelError, err, msg, kv)
+}
+
+// writeErr logs err with its causes, see errorFields.
+func (l *Logger) writeErr(level int, err error, msg string, kv []interface{}) error {
+	// the error is a field only if it is not the message
+	withErr := false
+	if err != nil {
+		if msg == "" {
+			msg = err.Error()
+		} else {
+			withErr = msg != err.Error()
+		}
+	}
+
+	frame, ok := l.caller(nil)
+	if !l.allow(level, frame, ok, msg) {
+		return nil
+	}
+
+	r := l.newRecord(level, msg)
+	// capped, so that appending copies instead of writing into the shared fields
+	fields := l.fields[:len(l.fields):len(l.fields)]
+	fields = appendFields(fields, kv)
+	r.Fields = appendErrorFields(fields, err, withErr)
+	if ok {
+		l.fillCaller(r, &frame)
+	}
+	l.fillStack(r)
+	if r.Stack == "" && err != nil {
+		r.Stack = errorStack(err)
+	}
+
+	return l.output(r)
+}
+
+// appendErrorFields appends "error" (if withErr), "causes" and the LogFields
+// of every error in the chain of err.
+func appendErrorFields(fields []Field, err error, withErr bool) []Field {
+	if err == nil {
+		return fields
+	}
+
+	if withErr {
+		fields = append(fields, Field{Key: "error", Value: err.Error()})
+	}
+
+	var buf [4]error
+	chain := errorChain(buf[:0], err)
+	if len(chain) > 1 {
+		causes := make([]string, 0, len(chain)-1)
+		for _, v := range chain[1:] {
+			causes = append(causes, v.Error())
+		}
+		fields = append(fields, Field{Key: "causes", Value: causes})
+	}
+
+	for _, v := range chain {
+		ef, ok := v.(ErrorFielder)
+		if !ok {
+			continue
+		}
+
+		m := ef.LogFields()
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, Field{Key: k, Value: m[k]})
+		}
+	}
+
+	return fields
+}
+
+// errorChain appends err followed by its causes to chain, found by
+// errors.Unwrap and by Unwrap() []error (errors.Join) depth first.
+func errorChain(chain []error, err error) []error {
+	if err == nil || len(chain) >= maxErrorCauses {
+		return chain
+	}
+	chain = append(chain, err)
+
+	switch v := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range v.Unwrap() {
+			chain = errorChain(chain, e)
+		}
+	default:
+		chain = errorChain(chain, errors.Unwrap(err))
+	}
+
+	return chain
+}
+
+// errorStack returns the stack carried by an error of the chain, which is
+// printed by "%+v" like github.com/pkg/errors does.
+func errorStack(err error) string {
+	var buf [4]error
+	for _, v := range errorChain(buf[:0], err) {
+		if _, ok := v.(fmt.Formatter); !ok {
+			continue
+		}
+
+		if s := fmt.Sprintf("%+v", v); s != v.Error() {
+			return s
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,87 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type userError struct {
+	id int
+}
+
+func (e *userError) Error() string { return "bad user" }
+
+func (e *userError) LogFields() map[string]interface{} {
+	return map[string]interface{}{"user_id": e.id, "code": 400}
+}
+
+func TestErrorChain(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+
+	base := &userError{id: 1}
+	err := fmt.Errorf("save: %w", errors.Join(base, errors.New("disk full")))
+	l.ErrError(err)
+	l.ErrorErr(err, "request failed", "path", "/user")
+	l.ErrWarn(nil)
+
+	if len(s.records) != 2 {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+
+	r := s.records[0]
+	if r.Message != err.Error() || r.Level != LevelError {
+		t.Fatalf("unexpected record %+v", r)
+	}
+	keys := []string{"causes", "code", "user_id"}
+	if len(r.Fields) != len(keys) {
+		t.Fatalf("unexpected fields %+v", r.Fields)
+	}
+	for i, k := range keys {
+		if r.Fields[i].Key != k {
+			t.Fatalf("unexpected fields %+v", r.Fields)
+		}
+	}
+	causes := r.Fields[0].Value.([]string)
+	if len(causes) != 3 || causes[1] != "bad user" || causes[2] != "disk full" {
+		t.Fatal("unexpected causes", causes)
+	}
+
+	r = s.records[1]
+	if r.Message != "request failed" || r.Fields[0].Key != "path" || r.Fields[1].Key != "error" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+
+	b := &bytes.Buffer{}
+	(&jsonFormatter{}).Format(b, &r)
+	if !strings.Contains(b.String(), `"causes":["bad user\ndisk full","bad user","disk full"]`) {
+		t.Fatal("unexpected json", b.String())
+	}
+}
+
+type stackError struct{}
+
+func (e stackError) Error() string { return "stack error" }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if s.Flag('+') {
+		fmt.Fprint(s, "stack error\nmain.main\n\tmain.go:1")
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
+
+func TestErrorStack(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+	l.ErrInfo(fmt.Errorf("wrap: %w", stackError{}))
+
+	if len(s.records) != 1 || !strings.HasSuffix(s.records[0].Stack, "main.go:1") {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
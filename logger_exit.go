@@ -0,0 +1,86 @@
+package logx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultFatalTimeout = 5 * time.Second
+
+var (
+	exitLock     sync.Mutex
+	exitHandlers []func()
+	exitFunc     = os.Exit
+	fatalTimeout = defaultFatalTimeout
+)
+
+// RegisterExitHandler adds fn to the handlers run by Fatal once the queued
+// records are written, before the outputs are closed and the process exits.
+// They run in registration order, within the fatal timeout.
+func RegisterExitHandler(fn func()) {
+	if fn == nil {
+		panic("logx: invalid exit handler")
+	}
+
+	exitLock.Lock()
+	exitHandlers = append(exitHandlers, fn)
+	exitLock.Unlock()
+}
+
+// SetExitFunc replaces os.Exit called by Fatal, nil restores os.Exit.
+// It is meant for tests, Fatal returns if fn returns.
+func SetExitFunc(fn func(code int)) {
+	if fn == nil {
+		fn = os.Exit
+	}
+
+	exitLock.Lock()
+	exitFunc = fn
+	exitLock.Unlock()
+}
+
+// SetFatalTimeout bounds how long Fatal waits for the outputs to be drained,
+// the exit handlers to run and the outputs to be closed, default is 5s.
+func SetFatalTimeout(d time.Duration) {
+	exitLock.Lock()
+	fatalTimeout = d
+	exitLock.Unlock()
+}
+
+// fatalExit flushes l, runs the exit handlers, closes l and exits.
+// A hanging handler or output only delays the exit by the fatal timeout.
+func (l *Logger) fatalExit() {
+	exitLock.Lock()
+	handlers := exitHandlers
+	exit, timeout := exitFunc, fatalTimeout
+	exitLock.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := waitContext(ctx, func() error {
+		// the Fatal record reaches the outputs even if a handler hangs
+		l.FlushContext(ctx)
+		for _, fn := range handlers {
+			runExitHandler(fn)
+		}
+		return l.Shutdown(ctx)
+	})
+	if err == context.DeadlineExceeded {
+		fmt.Fprintf(os.Stderr, "logx: fatal exit timeout after %v\n", timeout)
+	}
+	cancel()
+
+	exit(1)
+}
+
+func runExitHandler(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "logx: exit handler panic:%v\n", r)
+		}
+	}()
+
+	fn()
+}
@@ -0,0 +1,108 @@
+package logx
+
+import (
+	"testing"
+	"time"
+)
+
+type closeStorer struct {
+	recordStorer
+	destroyed bool
+}
+
+func (s *closeStorer) WriteRecord(r *Record) error {
+	time.Sleep(time.Millisecond) // keep records in the async queue
+	return s.recordStorer.WriteRecord(r)
+}
+
+func (s *closeStorer) Destroy() {
+	s.destroyed = true
+}
+
+func TestFatal(t *testing.T) {
+	code := -1
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+
+	// the handlers are global, drop the one of this test afterwards
+	exitLock.Lock()
+	old := exitHandlers
+	exitLock.Unlock()
+	t.Cleanup(func() {
+		exitLock.Lock()
+		exitHandlers = old
+		exitLock.Unlock()
+	})
+
+	handled := false
+	RegisterExitHandler(func() { handled = true })
+
+	s := &closeStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("close", s)
+	l.Async(10)
+	for i := 0; i < 5; i++ {
+		l.Info("info")
+	}
+	l.Fatal("fatal")
+
+	if code != 1 || !handled || !s.destroyed {
+		t.Fatal("unexpected exit", code, handled, s.destroyed)
+	}
+	if len(s.records) != 6 || s.records[5].Message != "fatal" {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
+
+func TestFatalHangingHandler(t *testing.T) {
+	code := -1
+	SetExitFunc(func(c int) { code = c })
+	defer SetExitFunc(nil)
+	SetFatalTimeout(50 * time.Millisecond)
+	defer SetFatalTimeout(defaultFatalTimeout)
+
+	exitLock.Lock()
+	old := exitHandlers
+	exitLock.Unlock()
+	t.Cleanup(func() {
+		exitLock.Lock()
+		exitHandlers = old
+		exitLock.Unlock()
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	RegisterExitHandler(func() { <-release }) // like a db.Close() which hangs
+
+	s := &blockingStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("blocking", s)
+	l.Async(10)
+	l.Fatal("fatal")
+
+	if code != 1 {
+		t.Fatal("unexpected exit", code)
+	}
+	// flushed before the handlers ran
+	if got := s.messages(); len(got) != 1 || got[0] != "fatal" {
+		t.Fatalf("unexpected records %q", got)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	s := &closeStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("close", s)
+	l.Async(10)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("no panic")
+		}
+		if len(s.records) != 1 || s.records[0].Message != "panic" {
+			t.Fatalf("unexpected records %+v", s.records)
+		}
+		l.Close()
+	}()
+	l.Panic("panic")
+}
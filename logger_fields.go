@@ -0,0 +1,44 @@
+package logx
+
+import (
+	"fmt"
+)
+
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to every record of a Logger.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// With returns a child logger carrying the given key/value pairs.
+// The child shares outputs and level with its parent.
+// kv like: "request_id", id, "user_id", uid
+func (l *Logger) With(kv ...interface{}) *Logger {
+	child := *l
+	child.fields = make([]Field, 0, len(l.fields)+len(kv)/2+1)
+	child.fields = append(child.fields, l.fields...)
+	child.fields = appendFields(child.fields, kv)
+
+	return &child
+}
+
+// appendFields pairs up kv and appends them to fields. A key that is not a
+// string is formatted with %v, a trailing value without key is stored under "!BADKEY".
+func appendFields(fields []Field, kv []interface{}) []Field {
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields = append(fields, Field{Key: badKey, Value: kv[i]})
+			break
+		}
+
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+
+	return fields
+}
@@ -0,0 +1,73 @@
+package logx
+
+import (
+	"fmt"
+)
+
+// hook is a callback added by AddHook.
+type hook struct {
+	levels map[int]bool // nil means all levels
+	fn     func(r *Record) error
+}
+
+// AddHook calls fn for every record at one of levels before it is written
+// to the adapters, nil levels means all levels. fn may modify the record, like
+// adding fields, but must not retain it. It runs in the async worker when
+// Async is enabled. An error or a panic is reported and the record is still
+// written.
+func (l *Logger) AddHook(levels []int, fn func(r *Record) error) {
+	if fn == nil {
+		panic("logx: invalid hook")
+	}
+
+	h := hook{fn: fn}
+	if levels != nil {
+		h.levels = make(map[int]bool, len(levels))
+		for _, v := range levels {
+			h.levels[v] = true
+		}
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var hooks []hook
+	if p := l.hooks.Load(); p != nil {
+		hooks = append(hooks, *p...)
+	}
+	hooks = append(hooks, h)
+	l.hooks.Store(&hooks)
+}
+
+func (l *Logger) runHooks(r *Record) {
+	p := l.hooks.Load()
+	if p == nil {
+		return
+	}
+
+	// the fields may be shared with the Logger, make appending copy them
+	r.Fields = r.Fields[:len(r.Fields):len(r.Fields)]
+
+	for _, h := range *p {
+		if h.levels != nil && !h.levels[r.Level] {
+			continue
+		}
+
+		if err := runHook(h.fn, r); err != nil {
+			l.stats.hookErrors.Add(1)
+			l.reportError("", OpHook, err)
+		}
+	}
+}
+
+// runHook calls fn, a panic is returned as error so that a bad hook can not
+// stop the caller or the async worker.
+func runHook(fn func(r *Record) error, r *Record) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("hook panic: %v", v)
+		}
+	}()
+
+	return fn(r)
+}
@@ -0,0 +1,95 @@
+package logx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.AddRecordStorer("record", s)
+
+	errCount := 0
+	log.AddHook([]int{LevelError}, func(r *Record) error {
+		errCount++
+		return errors.New("alerting is down")
+	})
+	log.AddHook(nil, func(r *Record) error {
+		r.Fields = append(r.Fields, Field{Key: "host", Value: "web1"})
+		return nil
+	})
+
+	child := log.With("k", "v")
+	child.Info("info")
+	child.Error("error")
+	log.Warn("warn")
+
+	if errCount != 1 {
+		t.Fatal("error hook ran", errCount, "times not 1")
+	}
+	if len(s.records) != 3 {
+		t.Fatal("got", len(s.records), "records not 3")
+	}
+	for _, r := range s.records {
+		if last := r.Fields[len(r.Fields)-1]; last.Key != "host" {
+			t.Fatalf("unexpected fields %+v", r.Fields)
+		}
+	}
+	if len(child.fields) != 1 || cap(child.fields) < 2 {
+		t.Fatal("unexpected child fields", child.fields)
+	}
+	if f := child.fields[:2]; f[1].Key == "host" {
+		t.Fatal("hook modified the fields of the logger")
+	}
+}
+
+func TestHookAsync(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.Async()
+	log.AddRecordStorer("record", s)
+	log.AddHook(nil, func(r *Record) error {
+		r.Message += "!"
+		return nil
+	})
+
+	log.Info("info")
+	log.Close()
+
+	if len(s.records) != 1 || s.records[0].Message != "info!" {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
+
+func TestHookPanic(t *testing.T) {
+	var reported []error
+	s := &recordStorer{}
+	log := NewLogger()
+	log.Async()
+	log.AddRecordStorer("record", s)
+	log.SetErrorHandler(func(adapter, op string, err error) {
+		reported = append(reported, err)
+	})
+	log.AddHook(nil, func(r *Record) error {
+		if r.Message == "boom" {
+			panic("bad hook")
+		}
+		return nil
+	})
+
+	log.Info("boom")
+	log.Info("info")
+	log.Close()
+
+	if len(s.records) != 2 {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+	if st := log.Stats(); st.HookErrors != 1 {
+		t.Fatal("got", st.HookErrors, "hook errors not 1")
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrHook) ||
+		reported[0].Error() != "logx: hook adapter() error:hook panic: bad hook" {
+		t.Fatal("got reported", reported)
+	}
+}
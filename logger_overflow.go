@@ -0,0 +1,184 @@
+package logx
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// OverflowPolicy decides what Async does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room in the queue, the default
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the new record
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued record to make room
+	OverflowDropOldest
+	// OverflowSync writes the new record in the calling goroutine,
+	// so it may be written before older queued records
+	OverflowSync
+)
+
+var overflowPolicies = map[string]OverflowPolicy{
+	"block":       OverflowBlock,
+	"drop-newest": OverflowDropNewest,
+	"drop-oldest": OverflowDropOldest,
+	"sync":        OverflowSync,
+}
+
+// parseOverflowPolicy parses the "overflow" of adapter configs, "" means block.
+func parseOverflowPolicy(s string) (OverflowPolicy, error) {
+	if s == "" {
+		return OverflowBlock, nil
+	}
+
+	p, ok := overflowPolicies[s]
+	if !ok {
+		return 0, fmt.Errorf("logx: unknown overflow policy %q", s)
+	}
+	return p, nil
+}
+
+// SetOverflowPolicy sets what happens to a record when the async queue is
+// full. timeout bounds the wait of OverflowBlock, the record is dropped once
+// it expires, default is waiting forever. Panic and Fatal records always wait.
+// Dropped records are counted by Stats and reported by a record once the
+// queue is drained.
+func (l *Logger) SetOverflowPolicy(p OverflowPolicy, timeout ...time.Duration) {
+	l.overflowPolicy.Store(int64(p))
+	l.overflowTimeout.Store(int64(append(timeout, 0)[0]))
+}
+
+// enqueue hands r to the async worker, following the overflow policy.
+// Once the worker is gone the records go to the stderr fallback.
+func (l *Logger) enqueue(r *Record) {
+	offer(l.msgChan, r, OverflowPolicy(l.overflowPolicy.Load()),
+		time.Duration(l.overflowTimeout.Load()), l.asyncDone, l.drop, l.writeNow)
+
+	// the worker may have exited before taking r, see Shutdown
+	if l.workerGone() {
+		l.drainQueue()
+	}
+}
+
+func (l *Logger) workerGone() bool {
+	select {
+	case <-l.asyncDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// drainQueue writes the records left in the queue by the exited worker
+// to the stderr fallback.
+func (l *Logger) drainQueue() {
+	for {
+		select {
+		case r := <-l.msgChan:
+			writeFallback(r)
+			putRecord(r)
+		default:
+			return
+		}
+	}
+}
+
+// offer sends r to ch following policy, a closed done gives up a blocked send.
+// drop is called for the dropped records and write for the records to write
+// at once, both own the record.
+func offer(ch chan *Record, r *Record, policy OverflowPolicy, timeout time.Duration,
+	done <-chan struct{}, drop, write func(r *Record)) {
+	if isPanicOrFatal(r.Level) {
+		policy, timeout = OverflowBlock, 0
+	}
+
+	select {
+	case ch <- r:
+		return
+	default:
+	}
+
+	switch policy {
+	case OverflowDropNewest:
+		drop(r)
+	case OverflowDropOldest:
+		for {
+			select {
+			case ch <- r:
+				return
+			default:
+			}
+
+			select {
+			case old := <-ch:
+				drop(old)
+			default:
+			}
+		}
+	case OverflowSync:
+		write(r)
+	default:
+		var expired <-chan time.Time
+		if timeout > 0 {
+			t := time.NewTimer(timeout)
+			defer t.Stop()
+			expired = t.C
+		}
+
+		select {
+		case ch <- r:
+		case <-expired:
+			drop(r)
+		case <-done:
+			drop(r)
+		}
+	}
+}
+
+func (l *Logger) writeNow(r *Record) {
+	if l.workerGone() {
+		writeFallback(r)
+	} else {
+		l.writeToLoggers(r)
+	}
+	putRecord(r)
+}
+
+// drop discards r, except Panic and Fatal records which are written at once,
+// and the records refused by the exited worker which go to the stderr fallback.
+func (l *Logger) drop(r *Record) {
+	if isPanicOrFatal(r.Level) || l.workerGone() {
+		l.writeNow(r)
+		return
+	}
+
+	l.stats.dropped.Add(1)
+	l.stats.pendingDropped.Add(1)
+	putRecord(r)
+}
+
+// writeDropped reports the records dropped since the last report,
+// it is called by the async worker once the queue is empty.
+func (l *Logger) writeDropped() {
+	n := l.stats.pendingDropped.Swap(0)
+	if n == 0 {
+		return
+	}
+
+	r := newDroppedRecord(n)
+	l.writeToLoggers(r)
+	putRecord(r)
+}
+
+func newDroppedRecord(n int64) *Record {
+	r := recordPool.Get().(*Record)
+	r.Time = time.Now()
+	r.Level = LevelWarn
+	r.Message = strconv.FormatInt(n, 10) + " messages dropped by async overflow"
+	r.Fields = []Field{{Key: "dropped", Value: n}}
+
+	return r
+}
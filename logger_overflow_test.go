@@ -0,0 +1,86 @@
+package logx
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingStorer blocks writing "1" until release is closed.
+type blockingStorer struct {
+	recordStorer
+	lock    sync.Mutex
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStorer) WriteRecord(r *Record) error {
+	if r.Message == "1" {
+		close(s.started)
+		<-s.release
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.recordStorer.WriteRecord(r)
+}
+
+func (s *blockingStorer) messages() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	msgs := []string{}
+	for _, r := range s.records {
+		msgs = append(msgs, r.Message)
+	}
+	return msgs
+}
+
+func testOverflow(t *testing.T, policy OverflowPolicy, timeout time.Duration, want []string) {
+	s := &blockingStorer{started: make(chan struct{}), release: make(chan struct{})}
+	log := NewLogger()
+	log.Async(2)
+	log.SetOverflowPolicy(policy, timeout)
+	log.AddRecordStorer("blocking", s)
+
+	log.Info("1")
+	<-s.started
+	for _, v := range []string{"2", "3", "4", "5"} {
+		log.Info(v)
+	}
+	close(s.release)
+	log.Close()
+
+	if got := s.messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	dropped := int64(0)
+	if want[len(want)-1] == "2 messages dropped by async overflow" {
+		dropped = 2
+	}
+	if st := log.Stats(); st.Dropped != dropped {
+		t.Fatal("got", st.Dropped, "dropped not", dropped)
+	}
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	testOverflow(t, OverflowDropNewest, 0,
+		[]string{"1", "2", "3", "2 messages dropped by async overflow"})
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	testOverflow(t, OverflowDropOldest, 0,
+		[]string{"1", "4", "5", "2 messages dropped by async overflow"})
+}
+
+func TestOverflowBlockTimeout(t *testing.T) {
+	testOverflow(t, OverflowBlock, 10*time.Millisecond,
+		[]string{"1", "2", "3", "2 messages dropped by async overflow"})
+}
+
+func TestOverflowSync(t *testing.T) {
+	testOverflow(t, OverflowSync, 0,
+		[]string{"4", "5", "1", "2", "3"})
+}
@@ -0,0 +1,163 @@
+package logx
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// outputQueue is the own queue of an adapter, its worker writes to the
+// adapter so that a slow adapter does not delay the others.
+type outputQueue struct {
+	nl      *nameLogger
+	core    *logCore // for the batch settings
+	ch      chan *Record
+	policy  OverflowPolicy
+	timeout time.Duration
+
+	signal chan asyncSignal
+	done   chan struct{} // closed when the worker exits
+
+	dropped        atomic.Int64
+	pendingDropped atomic.Int64 // not reported by a record yet
+	highWater      atomic.Int64
+}
+
+// SetAdapterQueue gives the adapters named adapterName their own queue of
+// length records, written by their own goroutine with the overflow policy p,
+// see SetOverflowPolicy. length <= 0 removes the queue, the queued records
+// are written first. It is the same as the "queue" config, like:
+// {"queue":1000,"overflow":"drop-newest","overflow_timeout":"100ms"}
+func (l *Logger) SetAdapterQueue(adapterName string, length int, p OverflowPolicy, timeout ...time.Duration) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	found := false
+	for _, v := range l.getOutputs() {
+		if v.name == adapterName {
+			v.setQueue(l.logCore, length, p, append(timeout, 0)[0])
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("logx: unknown adaptername %q", adapterName)
+	}
+
+	return nil
+}
+
+func (nl *nameLogger) setQueue(core *logCore, length int, p OverflowPolicy, timeout time.Duration) {
+	var q *outputQueue
+	if length > 0 {
+		q = &outputQueue{
+			nl:      nl,
+			core:    core,
+			ch:      make(chan *Record, length),
+			policy:  p,
+			timeout: timeout,
+			signal:  make(chan asyncSignal),
+			done:    make(chan struct{}),
+		}
+		go q.run()
+	}
+
+	if old := nl.queue.Swap(q); old != nil {
+		old.call("close")
+	}
+}
+
+// put queues a copy of r if its level passes, r stays owned by the caller.
+func (q *outputQueue) put(r *Record) {
+	if r.Level < int(q.nl.level.Load()) {
+		return
+	}
+
+	c := recordPool.Get().(*Record)
+	*c = *r
+	offer(q.ch, c, q.policy, q.timeout, q.done, q.drop, q.writeNow)
+
+	n := int64(len(q.ch))
+	for {
+		hw := q.highWater.Load()
+		if n <= hw || q.highWater.CompareAndSwap(hw, n) {
+			return
+		}
+	}
+}
+
+func (q *outputQueue) writeNow(r *Record) {
+	q.nl.write(r)
+	putRecord(r)
+}
+
+// drop discards r, except Panic and Fatal records which are written at once.
+func (q *outputQueue) drop(r *Record) {
+	if isPanicOrFatal(r.Level) {
+		q.writeNow(r)
+		return
+	}
+
+	q.dropped.Add(1)
+	q.pendingDropped.Add(1)
+	putRecord(r)
+}
+
+// call sends op to the worker and waits until it is handled,
+// it returns at once if the worker is gone.
+func (q *outputQueue) call(op string) error {
+	return sendSignal(q.signal, q.done, op)
+}
+
+func (q *outputQueue) run() {
+	defer close(q.done)
+
+	b := &batcher{}
+	for {
+		select {
+		case r := <-q.ch:
+			b.records = append(b.records, r)
+			sg := collect(q.ch, q.signal, b,
+				int(q.core.batchSize.Load()), time.Duration(q.core.batchWait.Load()))
+			q.nl.writeBatch(b)
+			b.recycle()
+
+			if len(q.ch) == 0 {
+				q.writeDropped()
+			}
+
+			if sg != nil && q.handleSignal(*sg) {
+				return
+			}
+		case sg := <-q.signal:
+			if q.handleSignal(sg) {
+				return
+			}
+		}
+	}
+}
+
+// handleSignal writes the queued records and flushes the adapter,
+// it reports whether the worker must exit.
+func (q *outputQueue) handleSignal(sg asyncSignal) bool {
+	b := &batcher{}
+	for takeQueued(q.ch, b, int(q.core.batchSize.Load())) {
+		q.nl.writeBatch(b)
+		b.recycle()
+	}
+	q.writeDropped()
+	*sg.err = q.nl.flushStorer()
+
+	close(sg.done)
+	return sg.op == "close"
+}
+
+func (q *outputQueue) writeDropped() {
+	n := q.pendingDropped.Swap(0)
+	if n == 0 {
+		return
+	}
+
+	r := newDroppedRecord(n)
+	q.nl.write(r)
+	putRecord(r)
+}
@@ -0,0 +1,47 @@
+package logx
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAdapterQueue(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		slow := &blockingStorer{started: make(chan struct{}), release: make(chan struct{})}
+		fast := &recordStorer{}
+		log := NewLogger()
+		if async {
+			log.Async()
+		}
+		log.AddRecordStorer("slow", slow, `{"queue":2,"overflow":"drop-newest"}`)
+		log.AddRecordStorer("fast", fast)
+
+		log.Info("1")
+		<-slow.started
+		for _, v := range []string{"2", "3", "4", "5"} {
+			log.Info(v)
+		}
+		// the fast adapter is not held up by the slow one,
+		// Flush would wait for the slow one
+		deadline := time.Now().Add(5 * time.Second)
+		for log.Stats().Adapters["fast"].Messages != 5 {
+			if time.Now().After(deadline) {
+				t.Fatalf("async %v: fast adapter is stalled", async)
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		st := log.Stats().Adapters["slow"]
+		if st.Dropped != 2 || st.QueueCapacity != 2 || st.QueueHighWater != 2 {
+			t.Fatalf("async %v: got %+v", async, st)
+		}
+		close(slow.release)
+		log.Close()
+
+		want := []string{"1", "2", "3", "2 messages dropped by async overflow"}
+		if got := slow.messages(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("async %v: got %q, want %q", async, got, want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package logx
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// run with: go test -race -run Race
+
+type discardStorer struct {
+	lg *logWriter
+}
+
+func (s *discardStorer) Init(config string) error {
+	s.lg = newLogWriter(io.Discard)
+	return nil
+}
+func (s *discardStorer) Destroy() {}
+func (s *discardStorer) Flush()   {}
+
+func (s *discardStorer) WriteRecord(r *Record) error {
+	return s.lg.println(r.Time, r.legacyMsg())
+}
+
+// testRaceReconfigure logs from several goroutines while l is reconfigured.
+func testRaceReconfigure(t *testing.T, l *Logger) {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := l.With("goroutine", i)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Debug("debug")
+				child.Infof("info %d", i)
+				l.Log(LevelWarn, "warn")
+			}
+		}(i)
+	}
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		l.AddRecordStorer("discard", &discardStorer{}, `{"level":"info"}`)
+		l.SetAdapterLevel("discard", LevelDebug)
+		l.SetLevel(i % (LevelError + 1))
+		l.SetShortfile(i%2 == 0)
+		l.SetFuncCallDepth(2 + i%2)
+		l.SetVModule("logger_race_test=debug")
+		SetTimeLayout("2006-01-02 15:04:05.000")
+		l.Flush()
+		l.DeleteLogger("discard")
+		l.SetVModule("")
+		if i%10 == 0 {
+			l.Reset()
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+	SetTimeLayout(defaultTimeLayout)
+}
+
+func TestRaceSync(t *testing.T) {
+	l := NewLogger()
+	testRaceReconfigure(t, l)
+	l.Close()
+}
+
+func TestRaceAsync(t *testing.T) {
+	l := NewLogger()
+	l.Async(100)
+	testRaceReconfigure(t, l)
+	l.Close()
+}
+
+func TestRaceAsyncFlush(t *testing.T) {
+	l := NewLogger()
+	l.AddRecordStorer("discard", &discardStorer{})
+	l.Async(10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("info")
+				l.Flush()
+			}
+		}()
+	}
+	wg.Wait()
+	l.Close()
+}
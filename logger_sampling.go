@@ -0,0 +1,226 @@
+package logx
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxSampleKeys bounds the counters of a sampler, messages of new keys
+// beyond it are not sampled.
+const maxSampleKeys = 4096
+
+// sampler keeps the first records of a key in every tick, then every
+// thereafter-th, see SetSampling.
+type sampler struct {
+	tick              time.Duration
+	first, thereafter int64
+
+	lock sync.Mutex // serializes changing counters
+	// copy-on-write so that sampling takes no lock
+	counters atomic.Pointer[map[sampleKey]*sampleCounter]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// sampleKey is the call site, or the format when the caller is unknown.
+type sampleKey struct {
+	level  int
+	pc     uintptr
+	format string
+}
+
+type sampleCounter struct {
+	frame  runtime.Frame // valid if the key has pc
+	format string        // the first one of the key
+
+	resetAt atomic.Int64 // unix nano the current tick ends
+	n       atomic.Int64 // records in the current tick
+	dropped atomic.Int64 // dropped since the last summary
+}
+
+// SetSampling limits the records of each call site: in every tick the first
+// ones are logged, then every thereafter-th, the rest is dropped.
+// thereafter <= 0 drops all of them. Records without a known caller are keyed
+// by their format. Every tick a record at the sampled level reports how many
+// messages of a call site were dropped. Panic and Fatal are never sampled.
+// tick <= 0 disables sampling.
+func (l *Logger) SetSampling(tick time.Duration, first, thereafter int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.stopSampler()
+	if tick <= 0 {
+		return
+	}
+	if first < 0 {
+		first = 0
+	}
+
+	s := &sampler{
+		tick:       tick,
+		first:      int64(first),
+		thereafter: int64(thereafter),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	l.sampler.Store(s)
+
+	go l.runSampler(s)
+}
+
+// stopSampler stops the sampler and reports what it dropped, l.lock must be held.
+func (l *Logger) stopSampler() {
+	s := l.sampler.Swap(nil)
+	if s == nil {
+		return
+	}
+
+	close(s.stop)
+	<-s.done
+}
+
+func (l *Logger) runSampler(s *sampler) {
+	defer close(s.done)
+
+	t := time.NewTicker(s.tick)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			l.writeSampleSummary(s)
+		case <-s.stop:
+			l.writeSampleSummary(s)
+			return
+		}
+	}
+}
+
+// sample reports whether the record is kept.
+func (s *sampler) sample(level int, frame *runtime.Frame, ok bool, format string) bool {
+	key := sampleKey{level: level}
+	if ok {
+		key.pc = frame.PC
+	} else {
+		key.format = format
+	}
+
+	c := s.counter(key, frame, ok, format)
+	if c == nil {
+		return true
+	}
+
+	n := c.inc(time.Now().UnixNano(), s.tick)
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	c.dropped.Add(1)
+	return false
+}
+
+func (s *sampler) counter(key sampleKey, frame *runtime.Frame, ok bool, format string) *sampleCounter {
+	if m := s.counters.Load(); m != nil {
+		if c, ok := (*m)[key]; ok {
+			return c
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var old map[sampleKey]*sampleCounter
+	if p := s.counters.Load(); p != nil {
+		old = *p
+	}
+	if c, ok := old[key]; ok {
+		return c
+	}
+	if len(old) >= maxSampleKeys {
+		return nil
+	}
+
+	c := &sampleCounter{format: format}
+	if ok {
+		c.frame = *frame
+	}
+
+	m := make(map[sampleKey]*sampleCounter, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[key] = c
+	s.counters.Store(&m)
+
+	return c
+}
+
+// inc counts a record at now, the first record after a tick starts a new one.
+func (c *sampleCounter) inc(now int64, tick time.Duration) int64 {
+	resetAt := c.resetAt.Load()
+	if now < resetAt {
+		return c.n.Add(1)
+	}
+
+	if c.resetAt.CompareAndSwap(resetAt, now+int64(tick)) {
+		c.n.Store(1)
+		return 1
+	}
+	return c.n.Add(1)
+}
+
+// writeSampleSummary logs the dropped count of every key and forgets the
+// keys idle for a whole tick.
+func (l *Logger) writeSampleSummary(s *sampler) {
+	p := s.counters.Load()
+	if p == nil {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	idle := 0
+	for key, c := range *p {
+		dropped := c.dropped.Swap(0)
+		if dropped == 0 {
+			if c.resetAt.Load()+int64(s.tick) < now {
+				idle++
+			}
+			continue
+		}
+
+		r := l.newRecord(key.level, strconv.FormatInt(dropped, 10)+" messages dropped by sampling")
+		r.Fields = append(l.fields[:len(l.fields):len(l.fields)], Field{Key: "dropped", Value: dropped})
+		if key.pc != 0 {
+			l.fillCaller(r, &c.frame)
+		}
+		// without caller the format tells the messages apart
+		if r.File == "" {
+			r.Fields = append(r.Fields, Field{Key: "format", Value: c.format})
+		}
+		l.output(r)
+	}
+
+	if idle > 0 {
+		s.forgetIdle(now)
+	}
+}
+
+// forgetIdle drops idle counters, a record racing with it may lose its dropped count.
+func (s *sampler) forgetIdle(now int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	old := *s.counters.Load()
+	m := make(map[sampleKey]*sampleCounter, len(old))
+	for k, v := range old {
+		if v.dropped.Load() == 0 && v.resetAt.Load()+int64(s.tick) < now {
+			continue
+		}
+		m[k] = v
+	}
+	s.counters.Store(&m)
+}
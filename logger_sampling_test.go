@@ -0,0 +1,66 @@
+package logx
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.AddRecordStorer("record", s)
+	log.SetSampling(time.Hour, 3, 10)
+
+	for i := 0; i < 100; i++ {
+		log.Warnf("retry %d", i)
+		if i%50 == 0 {
+			log.Info("other site")
+		}
+	}
+	// 1-3, then 13, 23, ... 93
+	if len(s.records) != 12+2 {
+		t.Fatal("got", len(s.records), "records not 14")
+	}
+
+	log.SetSampling(0, 0, 0)
+	last := s.records[len(s.records)-1]
+	if len(s.records) != 15 || last.Level != LevelWarn ||
+		last.Message != "88 messages dropped by sampling" ||
+		!strings.HasSuffix(last.File, "logger_sampling_test.go") {
+		t.Fatalf("unexpected summary %+v", last)
+	}
+
+	log.Warn("not sampled")
+	if len(s.records) != 16 {
+		t.Fatal("got", len(s.records), "records not 16")
+	}
+}
+
+func TestSamplingWithoutCaller(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.SetFuncCallDepth(0)
+	log.AddRecordStorer("record", s)
+	log.SetSampling(time.Hour, 1, 0)
+
+	for i := 0; i < 10; i++ {
+		log.Errorf("a %d", i)
+		log.Errorf("b %d", i)
+	}
+	log.Close()
+
+	if len(s.records) != 4 {
+		t.Fatal("got", len(s.records), "records not 4")
+	}
+	formats := map[interface{}]bool{}
+	for _, r := range s.records[2:] {
+		if r.File != "" || r.Message != "9 messages dropped by sampling" || len(r.Fields) != 2 {
+			t.Fatalf("unexpected summary %+v", r)
+		}
+		formats[r.Fields[1].Value] = true
+	}
+	if !formats["a %d"] || !formats["b %d"] {
+		t.Fatal("unexpected formats", formats)
+	}
+}
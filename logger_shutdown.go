@@ -0,0 +1,148 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// fallbackWriter receives the records logged after Shutdown.
+var fallbackWriter io.Writer = os.Stderr
+
+var fallbackFormatter = &textFormatter{}
+
+// FlushContext writes the queued records and flushes the outputs. It returns
+// the errors of the adapters implementing SyncCloser, or the error of ctx if
+// it is done first, the flush then goes on in the background.
+func (l *Logger) FlushContext(ctx context.Context) error {
+	return waitContext(ctx, func() error {
+		if l.msgChanLen.Load() > 0 {
+			return l.signal("flush")
+		}
+		return l.flush()
+	})
+}
+
+// Shutdown writes the queued records, then flushes and closes the outputs.
+// It returns the errors of the adapters implementing SyncCloser, or the error
+// of ctx if it is done first, the outputs are then closed in the background.
+// Records logged once Shutdown started are written to stderr. Calling it
+// again waits for the first call and returns the same errors.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	l.closeOnce.Do(func() {
+		go func() {
+			l.closeErr = l.close()
+			close(l.closeDone)
+		}()
+	})
+
+	select {
+	case <-l.closeDone:
+		return l.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Logger) close() error {
+	l.lock.Lock()
+	l.stopSampler()
+	l.lock.Unlock()
+
+	// the queued records are still written to the outputs
+	l.closed.Store(true)
+
+	if l.msgChanLen.Load() > 0 {
+		err := l.signal("close")
+		// records sent after the worker took the last ones
+		<-l.asyncDone
+		l.drainQueue()
+		return err
+	}
+	return errors.Join(l.flush(), l.destroyOutputs())
+}
+
+// waitContext runs fn and waits until it returns or ctx is done.
+func waitContext(ctx context.Context, fn func() error) error {
+	if ctx.Done() == nil {
+		return fn()
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- fn()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// writeFallback writes r to stderr, for the records logged after Shutdown.
+func writeFallback(r *Record) {
+	b := getBuffer()
+	fallbackFormatter.Format(b, r)
+	fallbackWriter.Write(b.Bytes())
+	putBuffer(b)
+}
+
+// Flush writes the queued records first, if nl has a queue.
+func (nl *nameLogger) Flush() {
+	nl.sync()
+}
+
+// sync is Flush returning the error of a SyncCloser.
+func (nl *nameLogger) sync() error {
+	if q := nl.queue.Load(); q != nil {
+		return q.call("flush")
+	}
+
+	return nl.flushStorer()
+}
+
+func (nl *nameLogger) flushStorer() error {
+	if d := nl.dedup.Load(); d != nil {
+		d.flush()
+	}
+
+	if sc, ok := nl.RecordStorer.(SyncCloser); ok {
+		return nl.wrapErr(sc.Sync())
+	}
+	nl.RecordStorer.Flush()
+	return nil
+}
+
+func (nl *nameLogger) Destroy() {
+	nl.close()
+}
+
+// close is Destroy returning the errors of a SyncCloser.
+func (nl *nameLogger) close() error {
+	var err error
+	if q := nl.queue.Swap(nil); q != nil {
+		err = q.call("close")
+	}
+
+	if d := nl.dedup.Swap(nil); d != nil {
+		d.flush()
+	}
+
+	if sc, ok := nl.RecordStorer.(SyncCloser); ok {
+		return errors.Join(err, nl.wrapErr(sc.Close()))
+	}
+	nl.RecordStorer.Destroy()
+	return err
+}
+
+func (nl *nameLogger) wrapErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("logx: adapter(%s): %w", nl.name, err)
+}
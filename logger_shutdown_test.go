@@ -0,0 +1,181 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeFailingStorer fails to sync and to close.
+type closeFailingStorer struct {
+	recordStorer
+}
+
+func (s *closeFailingStorer) Sync() error  { return errors.New("sync failed") }
+func (s *closeFailingStorer) Close() error { return errors.New("close failed") }
+
+func TestShutdown(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		s := &closeFailingStorer{}
+		log := NewLogger()
+		if async {
+			log.Async()
+		}
+		log.AddRecordStorer("record", &recordStorer{})
+		log.AddRecordStorer("failing", s)
+		log.Info("before")
+
+		err := log.FlushContext(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "adapter(failing): sync failed") {
+			t.Fatalf("async %v: FlushContext got %v", async, err)
+		}
+
+		err = log.Shutdown(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "adapter(failing): close failed") {
+			t.Fatalf("async %v: Shutdown got %v", async, err)
+		}
+		if len(s.records) != 1 {
+			t.Fatalf("async %v: got %d records, want 1", async, len(s.records))
+		}
+
+		// idempotent, later records go to stderr
+		if err2 := log.Shutdown(context.Background()); err2 != err {
+			t.Fatalf("async %v: second Shutdown got %v, want %v", async, err2, err)
+		}
+		log.Close()
+		log.Flush()
+
+		b := &bytes.Buffer{}
+		fallbackWriter = b
+		log.Info("after")
+		fallbackWriter = os.Stderr
+		if !strings.HasSuffix(b.String(), "] after\n") {
+			t.Fatalf("async %v: got fallback %q", async, b.String())
+		}
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	s := &blockingStorer{started: make(chan struct{}), release: make(chan struct{})}
+	log := NewLogger()
+	log.Async()
+	log.AddRecordStorer("blocking", s)
+
+	log.Info("1")
+	<-s.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := log.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatal("got", err)
+	}
+
+	close(s.release)
+	if err := log.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.messages(); len(got) != 1 {
+		t.Fatal("got", got)
+	}
+}
+
+// countStorer counts the records written to it.
+type countStorer struct {
+	recordStorer
+	n atomic.Int64
+}
+
+func (s *countStorer) WriteRecord(r *Record) error {
+	s.n.Add(1)
+	return nil
+}
+
+// countingWriter counts the lines written to it.
+type countingWriter struct {
+	lock  sync.Mutex
+	lines int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	w.lines += bytes.Count(p, []byte{'\n'})
+	w.lock.Unlock()
+	return len(p), nil
+}
+
+func TestShutdownWhileLogging(t *testing.T) {
+	fallback := &countingWriter{}
+	fallbackWriter = fallback
+	defer func() { fallbackWriter = os.Stderr }()
+
+	const goroutines, n = 8, 200
+	for i := 0; i < 20; i++ {
+		log := NewLogger()
+		log.Async(4)
+		log.SetFuncCallDepth(0)
+		s := &countStorer{}
+		log.AddRecordStorer("counter", s)
+		fallback.lines = 0
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < n; j++ {
+					log.Info("msg")
+				}
+			}()
+		}
+		log.Shutdown(context.Background())
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("logging hangs after Shutdown")
+		}
+
+		if got := int(s.n.Load()) + fallback.lines; got != goroutines*n {
+			t.Fatalf("got %d records, want %d", got, goroutines*n)
+		}
+	}
+}
+
+func TestEnqueueAfterShutdown(t *testing.T) {
+	fallback := &countingWriter{}
+	fallbackWriter = fallback
+	defer func() { fallbackWriter = os.Stderr }()
+
+	log := NewLogger()
+	log.Async(4)
+	log.Shutdown(context.Background())
+
+	// like a goroutine which passed the closed check before Shutdown
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			log.enqueue(log.newRecord(LevelInfo, "late"))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("enqueue hangs after Shutdown")
+	}
+
+	if fallback.lines != 10 {
+		t.Fatal("got", fallback.lines, "fallback records")
+	}
+}
@@ -0,0 +1,127 @@
+//go:build go1.21
+
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// SlogHandler is a slog.Handler writing to a Logger.
+// slog levels map onto LevelDebug..LevelError, attributes become fields
+// and groups prefix the keys of their attributes, like "req.id".
+type SlogHandler struct {
+	l      *Logger
+	fields []Field
+	prefix string
+}
+
+// NewSlogHandler returns a slog.Handler backed by l, like:
+//
+//	slog.SetDefault(slog.New(logx.NewSlogHandler(log)))
+func NewSlogHandler(l *Logger) *SlogHandler {
+	if l == nil {
+		panic("logx: invalid Logger")
+	}
+
+	return &SlogHandler{l: l}
+}
+
+func slogLevel(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.enabled(slogLevel(level))
+}
+
+func (h *SlogHandler) Handle(_ context.Context, sr slog.Record) error {
+	l := h.l
+	level := slogLevel(sr.Level)
+
+	var frame runtime.Frame
+	if sr.PC != 0 {
+		frame, _ = runtime.CallersFrames([]uintptr{sr.PC}).Next()
+	}
+	if !l.allow(level, frame, sr.PC != 0, sr.Message) {
+		return nil
+	}
+
+	r := l.newRecord(level, sr.Message)
+	if !sr.Time.IsZero() {
+		r.Time = sr.Time
+	}
+
+	fields := make([]Field, 0, len(l.fields)+len(h.fields)+sr.NumAttrs())
+	fields = append(fields, l.fields...)
+	fields = append(fields, h.fields...)
+	sr.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	r.Fields = fields
+
+	if sr.PC != 0 {
+		l.fillCaller(r, &frame)
+	}
+	l.fillStack(r)
+
+	return l.output(r)
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.fields = make([]Field, 0, len(h.fields)+len(attrs))
+	h2.fields = append(h2.fields, h.fields...)
+	for _, a := range attrs {
+		h2.fields = appendSlogAttr(h2.fields, h.prefix, a)
+	}
+
+	return &h2
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+
+	return &h2
+}
+
+// appendSlogAttr flattens a into fields, keys of group members are prefixed
+// with the group name.
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, v := range a.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, v)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
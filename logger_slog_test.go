@@ -0,0 +1,38 @@
+//go:build go1.21
+
+package logx
+
+import (
+	"log/slog"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	s := &recordStorer{}
+	log := NewLogger()
+	log.SetLevel(LevelInfo)
+	log.AddRecordStorer("record", s)
+
+	sl := slog.New(NewSlogHandler(log.With("app", "x")))
+	sl.Debug("debug")
+	sl.With("a", 1).WithGroup("req").Warn("warn", "id", 2, slog.Group("user", "name", "foo"))
+
+	if len(s.records) != 1 {
+		t.Fatal("got", len(s.records), "records not 1")
+	}
+	r := s.records[0]
+	if r.Level != LevelWarn || r.Message != "warn" || filepath.Base(r.File) != "logger_slog_test.go" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+
+	keys := []string{"app", "a", "req.id", "req.user.name"}
+	if len(r.Fields) != len(keys) {
+		t.Fatalf("unexpected fields %+v", r.Fields)
+	}
+	for i, k := range keys {
+		if r.Fields[i].Key != k {
+			t.Fatalf("unexpected fields %+v", r.Fields)
+		}
+	}
+}
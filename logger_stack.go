@@ -0,0 +1,90 @@
+package logx
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const maxStackDepth = 64
+
+// levelNone disables a level based feature, like SetStacktraceLevel.
+const levelNone = 1<<31 - 1
+
+// SetStacktraceLevel makes records at level or above carry the stack of the
+// calling goroutine, levelNone(default) disables it.
+func (l *Logger) SetStacktraceLevel(level int) {
+	l.stacktraceLevel.Store(int64(level))
+}
+
+func (l *Logger) GetStacktraceLevel() int {
+	return int(l.stacktraceLevel.Load())
+}
+
+// fillStack sets the stack of r if its level asks for one.
+func (l *Logger) fillStack(r *Record) {
+	if r.Level < l.GetStacktraceLevel() {
+		return
+	}
+
+	r.Stack = captureStack()
+}
+
+// captureStack renders the stack of the calling goroutine without the leading
+// frames of logx and of Helper functions, like:
+//
+//	main.handler
+//		/src/main.go:42
+//	main.main
+//		/src/main.go:10
+func captureStack() string {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(2, pcs[:])
+	if n == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs[:n])
+	leading := true
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+
+		// records of log/slog start in its Logger
+		if leading && (skipFrame(&frame) || strings.HasPrefix(frame.Function, "log/slog.")) {
+			if !more {
+				break
+			}
+			continue
+		}
+		leading = false
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+
+		if !more {
+			break
+		}
+	}
+
+	return b.String()
+}
+
+// writeIndentedStack writes every line of stack indented, one per line.
+func writeIndentedStack(b *bytes.Buffer, stack string) {
+	for _, line := range strings.Split(stack, "\n") {
+		b.WriteString("    ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+}
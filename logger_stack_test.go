@@ -0,0 +1,40 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStacktrace(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+	l.SetStacktraceLevel(LevelError)
+	l.Warn("warn")
+	l.Error("error")
+	sl := slog.New(NewSlogHandler(l))
+	sl.Warn("slog warn")
+	sl.Error("slog error")
+
+	if len(s.records) != 4 || s.records[0].Stack != "" || s.records[2].Stack != "" {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+	stack := s.records[1].Stack
+	if first := strings.SplitN(stack, "\n", 2)[0]; !strings.HasSuffix(first, ".TestStacktrace") ||
+		strings.Contains(stack, "writeMsg") {
+		t.Fatal("unexpected stack", stack)
+	}
+
+	stack = s.records[3].Stack
+	if first := strings.SplitN(stack, "\n", 2)[0]; !strings.HasSuffix(first, ".TestStacktrace") {
+		t.Fatal("unexpected slog stack", stack)
+	}
+
+	b := &bytes.Buffer{}
+	(&textFormatter{}).Format(b, &s.records[1])
+	if !strings.Contains(b.String(), "] error\n    ") || !strings.Contains(b.String(), ".TestStacktrace\n    \t") {
+		t.Fatal("unexpected text", b.String())
+	}
+}
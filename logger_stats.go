@@ -0,0 +1,260 @@
+package logx
+
+import (
+	"bytes"
+	"expvar"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of the counters of a Logger, see Logger.Stats.
+type Stats struct {
+	// Messages counts the records logged per level name
+	Messages map[string]int64
+	// Adapters holds the counters per adapter name
+	Adapters map[string]AdapterStats
+
+	// Sampled counts the records dropped by sampling, see SetSampling
+	Sampled int64
+	// HookErrors counts the errors returned by hooks, see AddHook
+	HookErrors int64
+	// Dropped counts the records dropped by the async overflow policy,
+	// see SetOverflowPolicy
+	Dropped int64
+
+	// the async queue, zero unless Async is enabled
+	QueueLength    int64
+	QueueCapacity  int64
+	QueueHighWater int64
+}
+
+// AdapterStats holds the counters of the adapters with the same name.
+type AdapterStats struct {
+	Messages int64
+	Errors   int64 // failed writes
+	// Bytes and Rotations are only reported by the builtin adapters
+	Bytes     int64
+	Rotations int64
+	// Failovers and Recoveries are only reported by the failover adapter
+	Failovers  int64
+	Recoveries int64
+
+	// the own queue of the adapters, zero unless they have one,
+	// see SetAdapterQueue
+	Dropped        int64
+	QueueLength    int64
+	QueueCapacity  int64
+	QueueHighWater int64
+}
+
+// statsAdapter is implemented by the adapters counting what they write.
+type statsAdapter interface {
+	adapterStats() (bytes, rotations int64)
+}
+
+// failoverAdapter is implemented by the failover adapter.
+type failoverAdapter interface {
+	failoverStats() (failovers, recoveries int64)
+}
+
+// loggerStats are the counters of logCore.
+type loggerStats struct {
+	lock sync.Mutex // serializes adding a level
+	// level -> count, copy-on-write so that counting takes no lock
+	levels atomic.Pointer[map[int]*atomic.Int64]
+
+	sampled        atomic.Int64
+	hookErrors     atomic.Int64
+	dropped        atomic.Int64
+	pendingDropped atomic.Int64 // not reported by a record yet
+	queueHighWater atomic.Int64
+}
+
+func (s *loggerStats) countLevel(level int) {
+	if p := s.levels.Load(); p != nil {
+		if c, ok := (*p)[level]; ok {
+			c.Add(1)
+			return
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var old map[int]*atomic.Int64
+	if p := s.levels.Load(); p != nil {
+		old = *p
+	}
+	if c, ok := old[level]; ok {
+		c.Add(1)
+		return
+	}
+
+	m := make(map[int]*atomic.Int64, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	c := &atomic.Int64{}
+	c.Add(1)
+	m[level] = c
+	s.levels.Store(&m)
+}
+
+// countQueue records the depth of the async queue after a send.
+func (s *loggerStats) countQueue(n int64) {
+	for {
+		hw := s.queueHighWater.Load()
+		if n <= hw || s.queueHighWater.CompareAndSwap(hw, n) {
+			return
+		}
+	}
+}
+
+// Stats returns the counters of l, they are shared by its children.
+func (l *Logger) Stats() Stats {
+	st := Stats{
+		Messages:       map[string]int64{},
+		Adapters:       map[string]AdapterStats{},
+		Sampled:        l.stats.sampled.Load(),
+		HookErrors:     l.stats.hookErrors.Load(),
+		Dropped:        l.stats.dropped.Load(),
+		QueueCapacity:  l.msgChanLen.Load(),
+		QueueHighWater: l.stats.queueHighWater.Load(),
+	}
+	if st.QueueCapacity > 0 {
+		st.QueueLength = int64(len(l.msgChan))
+	}
+
+	if p := l.stats.levels.Load(); p != nil {
+		for k, v := range *p {
+			st.Messages[levelName(k)] += v.Load()
+		}
+	}
+
+	for _, v := range l.getOutputs() {
+		as := st.Adapters[v.name]
+		as.Messages += v.messages.Load()
+		as.Errors += v.errors.Load()
+		if sa, ok := v.RecordStorer.(statsAdapter); ok {
+			n, rotations := sa.adapterStats()
+			as.Bytes += n
+			as.Rotations += rotations
+		}
+		if fa, ok := v.RecordStorer.(failoverAdapter); ok {
+			failovers, recoveries := fa.failoverStats()
+			as.Failovers += failovers
+			as.Recoveries += recoveries
+		}
+		if q := v.queue.Load(); q != nil {
+			as.Dropped += q.dropped.Load()
+			as.QueueLength += int64(len(q.ch))
+			as.QueueCapacity += int64(cap(q.ch))
+			as.QueueHighWater += q.highWater.Load()
+		}
+		st.Adapters[v.name] = as
+	}
+
+	return st
+}
+
+// PublishExpvar publishes the Stats of l as the expvar name,
+// which must not be published yet.
+func (l *Logger) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return l.Stats()
+	}))
+}
+
+// MetricsHandler serves the Stats of l in the Prometheus text format.
+func (l *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		b := &bytes.Buffer{}
+		writeMetrics(b, l.Stats())
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(b.Bytes())
+	})
+}
+
+func writeMetrics(b *bytes.Buffer, st Stats) {
+	writeMetricHeader(b, "logx_messages_total", "counter", "Records logged per level.")
+	for _, k := range sortedKeys(st.Messages) {
+		writeMetric(b, "logx_messages_total", "level", k, st.Messages[k])
+	}
+
+	adapters := make([]string, 0, len(st.Adapters))
+	for k := range st.Adapters {
+		adapters = append(adapters, k)
+	}
+	sort.Strings(adapters)
+	for _, m := range []struct {
+		name, typ, help string
+		value           func(AdapterStats) int64
+	}{
+		{"logx_adapter_messages_total", "counter", "Records written per adapter.", func(s AdapterStats) int64 { return s.Messages }},
+		{"logx_adapter_errors_total", "counter", "Failed writes per adapter.", func(s AdapterStats) int64 { return s.Errors }},
+		{"logx_adapter_bytes_total", "counter", "Bytes written per adapter.", func(s AdapterStats) int64 { return s.Bytes }},
+		{"logx_adapter_rotations_total", "counter", "File rotations per adapter.", func(s AdapterStats) int64 { return s.Rotations }},
+		{"logx_adapter_failovers_total", "counter", "Switches of a failover adapter to a fallback adapter.", func(s AdapterStats) int64 { return s.Failovers }},
+		{"logx_adapter_recoveries_total", "counter", "Switches of a failover adapter back to a preferred adapter.", func(s AdapterStats) int64 { return s.Recoveries }},
+		{"logx_adapter_dropped_total", "counter", "Records dropped by the queue of an adapter.", func(s AdapterStats) int64 { return s.Dropped }},
+		{"logx_adapter_queue_length", "gauge", "Records in the queue of an adapter.", func(s AdapterStats) int64 { return s.QueueLength }},
+		{"logx_adapter_queue_capacity", "gauge", "Capacity of the queue of an adapter.", func(s AdapterStats) int64 { return s.QueueCapacity }},
+		{"logx_adapter_queue_high_water", "gauge", "Most records seen in the queue of an adapter.", func(s AdapterStats) int64 { return s.QueueHighWater }},
+	} {
+		writeMetricHeader(b, m.name, m.typ, m.help)
+		for _, k := range adapters {
+			writeMetric(b, m.name, "adapter", k, m.value(st.Adapters[k]))
+		}
+	}
+
+	for _, m := range []struct {
+		name, typ, help string
+		value           int64
+	}{
+		{"logx_sampled_total", "counter", "Records dropped by sampling.", st.Sampled},
+		{"logx_hook_errors_total", "counter", "Errors returned by hooks.", st.HookErrors},
+		{"logx_dropped_total", "counter", "Records dropped by the async overflow policy.", st.Dropped},
+		{"logx_queue_length", "gauge", "Records in the async queue.", st.QueueLength},
+		{"logx_queue_capacity", "gauge", "Capacity of the async queue.", st.QueueCapacity},
+		{"logx_queue_high_water", "gauge", "Most records seen in the async queue.", st.QueueHighWater},
+	} {
+		writeMetricHeader(b, m.name, m.typ, m.help)
+		writeMetric(b, m.name, "", "", m.value)
+	}
+}
+
+// labelEscaper escapes label values as the text exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeMetricHeader(b *bytes.Buffer, name, typ, help string) {
+	b.WriteString("# HELP " + name + " " + help + "\n")
+	b.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+// writeMetric writes a sample, label is omitted if empty.
+func writeMetric(b *bytes.Buffer, name, label, value string, v int64) {
+	b.WriteString(name)
+	if label != "" {
+		b.WriteString("{" + label + "=\"")
+		b.WriteString(labelEscaper.Replace(value))
+		b.WriteString("\"}")
+	}
+	b.WriteByte(' ')
+	b.WriteString(strconv.FormatInt(v, 10))
+	b.WriteByte('\n')
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
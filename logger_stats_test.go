@@ -0,0 +1,98 @@
+package logx
+
+import (
+	"errors"
+	"expvar"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var expvarRuns int
+
+type failingStorer struct {
+	recordStorer
+}
+
+func (s *failingStorer) WriteRecord(r *Record) error {
+	return errors.New("disk full")
+}
+
+func TestStats(t *testing.T) {
+	log := NewLogger()
+	log.AddRecordStorer("record", &recordStorer{})
+	log.AddRecordStorer("failing", &failingStorer{}, `{"level":"error"}`)
+	log.AddLogger(AdapterFile, `{"daily":false,"maxline":2,"filename":"`+
+		filepath.ToSlash(filepath.Join(t.TempDir(), "stats.log"))+`"}`)
+	log.AddHook([]int{LevelWarn}, func(r *Record) error { return errors.New("hook") })
+
+	for i := 0; i < 3; i++ {
+		log.Info("info")
+	}
+	log.Warn("warn")
+	log.Error("error")
+
+	st := log.Stats()
+	if st.Messages["info"] != 3 || st.Messages["warn"] != 1 || st.Messages["error"] != 1 {
+		t.Fatal("unexpected messages", st.Messages)
+	}
+	if as := st.Adapters["record"]; as.Messages != 5 || as.Errors != 0 {
+		t.Fatalf("unexpected record stats %+v", as)
+	}
+	if as := st.Adapters["failing"]; as.Messages != 0 || as.Errors != 1 {
+		t.Fatalf("unexpected failing stats %+v", as)
+	}
+	if as := st.Adapters[AdapterFile]; as.Messages != 5 || as.Bytes == 0 || as.Rotations != 2 {
+		t.Fatalf("unexpected file stats %+v", as)
+	}
+	if st.HookErrors != 1 || st.QueueCapacity != 0 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	log.Close()
+}
+
+func TestStatsAsync(t *testing.T) {
+	log := NewLogger()
+	log.Async(10)
+	log.AddRecordStorer("record", &recordStorer{})
+	log.Info("info")
+	log.Flush()
+
+	st := log.Stats()
+	if st.QueueCapacity != 10 || st.QueueLength != 0 || st.QueueHighWater < 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+	log.Close()
+}
+
+func TestMetricsHandler(t *testing.T) {
+	log := NewLogger()
+	log.AddRecordStorer("record", &recordStorer{})
+	log.AddRecordStorer("中文\"\\\n", &recordStorer{})
+	log.Info("info")
+	// expvar.Publish panics on a name published before, like with -count
+	expvarRuns++
+	name := t.Name() + "_" + strconv.Itoa(expvarRuns)
+	log.PublishExpvar(name)
+
+	w := httptest.NewRecorder()
+	log.MetricsHandler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	for _, v := range []string{
+		"# TYPE logx_messages_total counter\n",
+		`logx_messages_total{level="info"} 1` + "\n",
+		`logx_adapter_messages_total{adapter="record"} 1` + "\n",
+		`logx_adapter_messages_total{adapter="中文\"\\\n"} 1` + "\n",
+		"logx_queue_capacity 0\n",
+	} {
+		if !strings.Contains(w.Body.String(), v) {
+			t.Fatalf("%q not in\n%s", v, w.Body.String())
+		}
+	}
+
+	if v := expvar.Get(name).String(); !strings.Contains(v, `"Messages":{"info":1}`) {
+		t.Fatal("unexpected expvar", v)
+	}
+}
@@ -0,0 +1,122 @@
+package logx
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maxWriterLine is the longest line kept by Writer, a longer one is logged
+// in pieces of this length.
+const maxWriterLine = 64 << 10
+
+// Writer returns an io.WriteCloser which splits the written bytes into lines
+// and logs one record per line at level. An incomplete line is kept until its
+// newline arrives or it exceeds 64KiB, Close logs it.
+func (l *Logger) Writer(level int) io.WriteCloser {
+	return &lineWriter{l: l, level: level}
+}
+
+// StdLogger returns a standard library logger writing to l at level,
+// like http.Server.ErrorLog.
+func (l *Logger) StdLogger(level int) *log.Logger {
+	return log.New(l.Writer(level), "", 0)
+}
+
+// RedirectStdLog sends the output of the global log package to the default
+// logger at level. It returns a function which restores the previous setup.
+func RedirectStdLog(level int) func() {
+	flags, prefix, w := log.Flags(), log.Prefix(), log.Writer()
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(defaultLogger.Writer(level))
+
+	return func() {
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+		log.SetOutput(w)
+	}
+}
+
+type lineWriter struct {
+	sync.Mutex
+	l     *Logger
+	level int
+	buf   []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	start := 0
+	for {
+		i := bytes.IndexByte(w.buf[start:], '\n')
+		if i < 0 {
+			break
+		}
+
+		line := bytes.TrimSuffix(w.buf[start:start+i], []byte{'\r'})
+		start += i + 1
+		if len(line) > 0 {
+			w.writeLine(string(line))
+		}
+	}
+
+	for len(w.buf)-start > maxWriterLine {
+		n := maxWriterLine
+		// do not split a UTF-8 sequence
+		for n > maxWriterLine-utf8.UTFMax && !utf8.RuneStart(w.buf[start+n]) {
+			n--
+		}
+		w.writeLine(string(w.buf[start : start+n]))
+		start += n
+	}
+
+	// keep the incomplete line at the front of buf
+	w.buf = append(w.buf[:0], w.buf[start:]...)
+
+	return len(p), nil
+}
+
+// Close logs the incomplete line, w may still be written afterwards.
+func (w *lineWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	line := bytes.TrimSuffix(w.buf, []byte{'\r'})
+	if len(line) > 0 {
+		w.writeLine(string(line))
+	}
+	w.buf = w.buf[:0]
+
+	return nil
+}
+
+func (w *lineWriter) writeLine(msg string) {
+	l := w.l
+
+	frame, ok := l.caller(isStdLogFrame)
+	if !l.allow(w.level, frame, ok, msg) {
+		return
+	}
+
+	r := l.newRecord(w.level, msg)
+	if ok {
+		l.fillCaller(r, &frame)
+	}
+	l.output(r)
+}
+
+// isStdLogFrame reports whether f belongs to the standard log package,
+// so StdLogger reports the caller of log.Printf.
+func isStdLogFrame(f *runtime.Frame) bool {
+	return strings.HasPrefix(f.Function, "log.")
+}
@@ -0,0 +1,62 @@
+package logx
+
+import (
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.SetLevel(LevelInfo)
+	l.AddRecordStorer("record", s)
+
+	l.StdLogger(LevelWarn).Printf("a\nb")
+	w := l.Writer(LevelInfo)
+	w.Write([]byte("c\r\n\nd"))
+	w.Write([]byte("e\n"))
+	l.Writer(LevelDebug).Write([]byte("debug\n"))
+	w.Write([]byte("last line without newline"))
+	w.Close()
+
+	// a line without newline is logged in pieces
+	long := l.Writer(LevelInfo)
+	long.Write([]byte(strings.Repeat("x", maxWriterLine)))
+	long.Write([]byte("y"))
+	if len(long.(*lineWriter).buf) != 1 {
+		t.Fatal("got buffered", len(long.(*lineWriter).buf))
+	}
+	long.Close()
+
+	msgs := []string{"a", "b", "c", "de", "last line without newline", strings.Repeat("x", maxWriterLine), "y"}
+	if len(s.records) != len(msgs) {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+	for i, msg := range msgs {
+		if s.records[i].Message != msg {
+			t.Fatalf("unexpected records %+v", s.records)
+		}
+	}
+	if s.records[0].Level != LevelWarn || s.records[2].Level != LevelInfo {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	s := &recordStorer{}
+	old := defaultLogger
+	l := NewLogger()
+	l.AddRecordStorer("record", s)
+	SetOutput(l)
+	defer SetOutput(old)
+
+	restore := RedirectStdLog(LevelInfo)
+	log.Print("std")
+	restore()
+	log.Print("restored")
+
+	if len(s.records) != 1 || s.records[0].Message != "std" {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+}
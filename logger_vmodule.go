@@ -0,0 +1,114 @@
+package logx
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// vmodule holds per file level overrides, see SetVModule.
+type vmodule struct {
+	rules []vmoduleRule
+	// call site pc -> level, noVModuleRule when no rule matches
+	cache sync.Map
+}
+
+type vmoduleRule struct {
+	pattern string // without ".go"
+	parts   int    // number of path elements in pattern
+	level   int
+}
+
+const noVModuleRule = -1 << 31
+
+// SetVModule overrides the level of the logger for matching source files.
+// spec like: "db/*=debug,http/router.go=warn"
+// A pattern is matched against the last path elements of the caller file,
+// as many as the pattern has, with or without ".go". The first matching rule
+// wins. An empty spec removes all rules.
+func (l *Logger) SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	l.vmodule.Store(vm)
+	return nil
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	vm := &vmodule{}
+	for _, v := range strings.Split(spec, ",") {
+		pattern, name, ok := strings.Cut(strings.TrimSpace(v), "=")
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), ".go")
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("logx: invalid vmodule rule %q", v)
+		}
+
+		level, ok := lookupLevel(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("logx: unknown level %q in vmodule rule %q", name, v)
+		}
+
+		// reject malformed globs now instead of on every match
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("logx: invalid vmodule pattern %q: %v", pattern, err)
+		}
+
+		vm.rules = append(vm.rules, vmoduleRule{
+			pattern: pattern,
+			parts:   strings.Count(pattern, "/") + 1,
+			level:   level,
+		})
+	}
+
+	return vm, nil
+}
+
+// level returns the level of the rule matching the file of frame.
+func (vm *vmodule) level(frame *runtime.Frame) (int, bool) {
+	if v, ok := vm.cache.Load(frame.PC); ok {
+		level := v.(int)
+		return level, level != noVModuleRule
+	}
+
+	level := vm.match(frame.File)
+	vm.cache.Store(frame.PC, level)
+
+	return level, level != noVModuleRule
+}
+
+func (vm *vmodule) match(file string) int {
+	file = strings.TrimSuffix(file, ".go")
+
+	for _, v := range vm.rules {
+		if ok, _ := path.Match(v.pattern, lastPathElems(file, v.parts)); ok {
+			return v.level
+		}
+	}
+
+	return noVModuleRule
+}
+
+// lastPathElems returns the last n elements of p, like ("a/b/c", 2) -> "b/c".
+func lastPathElems(p string, n int) string {
+	i := len(p)
+	for ; n > 0 && i > 0; n-- {
+		i = strings.LastIndexByte(p[:i], '/')
+		if i < 0 {
+			return p
+		}
+	}
+	if i <= 0 {
+		return p
+	}
+
+	return p[i+1:]
+}
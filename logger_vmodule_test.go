@@ -0,0 +1,61 @@
+package logx
+
+import (
+	"testing"
+)
+
+func TestVModule(t *testing.T) {
+	s := &recordStorer{}
+	l := NewLogger()
+	l.SetLevel(LevelError)
+	l.AddRecordStorer("record", s)
+
+	if err := l.SetVModule("other/*=warn,*_test.go=debug"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		l.Debug("debug")
+	}
+
+	if err := l.SetVModule("logger_vmodule_test=error"); err != nil {
+		t.Fatal(err)
+	}
+	l.SetLevel(LevelDebug)
+	l.Warn("warn")
+
+	l.SetVModule("")
+	l.Info("info")
+
+	msgs := []string{"debug", "debug", "info"}
+	if len(s.records) != len(msgs) {
+		t.Fatalf("unexpected records %+v", s.records)
+	}
+	for i, msg := range msgs {
+		if s.records[i].Message != msg {
+			t.Fatalf("unexpected records %+v", s.records)
+		}
+	}
+
+	for _, spec := range []string{"db", "db=nope", "[=debug"} {
+		if err := l.SetVModule(spec); err == nil {
+			t.Fatal("invalid vmodule", spec, "accepted")
+		}
+	}
+}
+
+func TestLastPathElems(t *testing.T) {
+	cases := []struct {
+		p    string
+		n    int
+		want string
+	}{
+		{"/src/db/conn", 1, "conn"},
+		{"/src/db/conn", 2, "db/conn"},
+		{"db/conn", 3, "db/conn"},
+	}
+	for _, v := range cases {
+		if got := lastPathElems(v.p, v.n); got != v.want {
+			t.Fatal(v.p, v.n, "got", got, "not", v.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package logx
+
+// raceEnabled skips the allocation tests, the race detector makes sync.Pool drop items.
+const raceEnabled = true
@@ -4,20 +4,55 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 )
 
-var (
-	timeLayout = "2006-01-02 15:04:05"
-)
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+var timeLayout atomic.Pointer[string]
 
 func SetTimeLayout(l string) {
-	timeLayout = l
+	timeLayout.Store(&l)
 }
 
+func getTimeLayout() string {
+	if p := timeLayout.Load(); p != nil {
+		return *p
+	}
+
+	return defaultTimeLayout
+}
+
+// fmtStrs caches the format strings of generateFmtStr.
+var fmtStrs = func() []string {
+	s := make([]string, 16)
+	for i := range s {
+		s[i] = strings.TrimRight(strings.Repeat("%v ", i), " ")
+	}
+	return s
+}()
+
 func generateFmtStr(n int) string {
+	if n < len(fmtStrs) {
+		return fmtStrs[n]
+	}
+
 	return strings.TrimRight(strings.Repeat("%v ", n), " ")
 }
 
+// sprintf is fmt.Sprintf, skipping it for a single string like Info("msg").
+func sprintf(format string, v []interface{}) string {
+	if format == "%v" && len(v) == 1 {
+		if s, ok := v[0].(string); ok {
+			return s
+		}
+	}
+
+	return fmt.Sprintf(format, v...)
+}
+
+// LevelMap maps the builtin level names to levels, it must not be modified.
+// The levels added by RegisterLevel are not in it, see GetLevelByName.
 var LevelMap = map[string]int{
 	"debug": LevelDebug,
 	"info":  LevelInfo,
@@ -28,11 +63,11 @@ var LevelMap = map[string]int{
 }
 
 func GetLevelByName(name string) int {
-	v, ok := LevelMap[name]
+	v, ok := lookupLevel(name)
 	if ok {
 		return v
 	} else {
-		panic(fmt.Sprintf("unknown Level(%s)", v))
+		panic(fmt.Sprintf("unknown Level(%s)", name))
 	}
 }
 